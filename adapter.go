@@ -1,5 +1,25 @@
 package dbm
 
+import "context"
+
 type Adapter interface {
 	Build(migration interface{}) string
 }
+
+// Executor can be implemented by adapters that need to run migrations which can't be built as queries upfront,
+// such as table rebuilds on SQLite. Exec returns false when the migration should be built and executed as usual.
+type Executor interface {
+	Exec(ctx context.Context, db Database, migration interface{}) (bool, error)
+}
+
+// Transactional can be implemented by adapters that have migrations which can't run in a transaction,
+// such as full-text indexes on MSSQL. Versions that include them run outside a transaction.
+type Transactional interface {
+	Transactional(migration interface{}) bool
+}
+
+// Validator can be implemented by adapters to reject migrations that can't be built into valid queries.
+// Every migration of a version is validated before any of them is executed.
+type Validator interface {
+	Validate(migration interface{}) error
+}
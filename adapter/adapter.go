@@ -5,63 +5,85 @@ import (
 	"github.com/jiyeyuran/dbm/adapter/sql/builder"
 )
 
-var SQLite3 = func() *sql.SQL {
+var SQLite3 = NewSQLite3(SQLite3Options{})
+
+// NewSQLite3 returns SQLite3 adapter configured using options.
+func NewSQLite3(options SQLite3Options) *sql.SQL {
 	var (
-		sqlite3          = sqlite3{}
-		ddlBufferFactory = builder.BufferFactory{InlineValues: true, BoolTrueValue: "1", BoolFalseValue: "0", Quoter: builder.Quote{IDPrefix: "\"", IDSuffix: "\"", IDSuffixEscapeChar: "\"", ValueQuote: "'", ValueQuoteEscapeChar: "'"}}
-		tableBuilder     = builder.Table{BufferFactory: ddlBufferFactory, ColumnMapper: sqlite3.columnMapper, DefinitionFilter: sqlite3.definitionFilter}
-		indexBuilder     = builder.Index{BufferFactory: ddlBufferFactory}
+		sqlite3          = sqlite3{Quote: builder.Quote{IDPrefix: "\"", IDSuffix: "\"", IDSuffixEscapeChar: "\"", ValueQuote: "'", ValueQuoteEscapeChar: "'"}, options: options}
+		ddlBufferFactory = builder.BufferFactory{InlineValues: true, BoolTrueValue: "1", BoolFalseValue: "0", Quoter: sqlite3}
+		tableBuilder     = builder.Table{BufferFactory: ddlBufferFactory, ColumnMapper: sqlite3.columnMapper, DefinitionFilter: sqlite3.definitionFilter, IdentityResetWriter: sqlite3.identityResetWriter, CommentWriter: sqlite3.commentWriter}
+		indexBuilder     = builder.Index{BufferFactory: ddlBufferFactory, FullTextIndexWriter: sqlite3.fullTextIndexWriter, CommentWriter: sqlite3.commentWriter}
 	)
 	return &sql.SQL{
 		TableBuilder: tableBuilder,
 		IndexBuilder: indexBuilder,
 		ErrorMapper:  sqlite3.errorMapper,
+		Validator:    sqlite3.validate,
+		Executor:     sqlite3.executor(tableBuilder),
 	}
-}()
+}
+
+var MYSQL = NewMySQL(MySQLOptions{})
 
-var MYSQL = func() *sql.SQL {
+// NewMySQL returns MySQL adapter configured using options.
+func NewMySQL(options MySQLOptions) *sql.SQL {
 	var (
-		mysql            = mysql{}
+		mysql            = mysql{options: options}
 		ddlBufferFactory = builder.BufferFactory{InlineValues: true, BoolTrueValue: "true", BoolFalseValue: "false", Quoter: mysql, ValueConverter: mysql}
-		tableBuilder     = builder.Table{BufferFactory: ddlBufferFactory, ColumnMapper: mysql.columnMapper, DropKeyMapper: mysql.dropKeyMapper}
-		indexBuilder     = builder.Index{BufferFactory: ddlBufferFactory, DropIndexOnTable: true}
+		tableBuilder     = builder.Table{BufferFactory: ddlBufferFactory, ColumnMapper: mysql.columnMapper, DropKeyMapper: mysql.dropKeyMapper, AlterColumnWriter: mysql.alterColumnWriter, AlterKeyWriter: mysql.alterKeyWriter}
+		indexBuilder     = builder.Index{BufferFactory: ddlBufferFactory, DropIndexOnTable: true, UsingAfterColumns: true, RenameIndexWriter: mysql.renameIndexWriter}
 	)
 	return &sql.SQL{
 		TableBuilder: tableBuilder,
 		IndexBuilder: indexBuilder,
 		ErrorMapper:  mysql.errorMapper,
+		Validator:    mysql.validate,
 	}
-}()
+}
+
+var MSSQL = NewMSSQL(MSSQLOptions{})
 
-var MSSQL = func() *sql.SQL {
+// NewMSSQL returns MSSQL adapter configured using options.
+func NewMSSQL(options MSSQLOptions) *sql.SQL {
 	var (
-		mssql            = mssql{}
-		ddlBufferFactory = builder.BufferFactory{InlineValues: true, BoolTrueValue: "1", BoolFalseValue: "0", Quoter: builder.Quote{IDPrefix: "[", IDSuffix: "]", IDSuffixEscapeChar: "]", ValueQuote: "'", ValueQuoteEscapeChar: "'"}}
-		tableBuilder     = builder.Table{BufferFactory: ddlBufferFactory, ColumnMapper: mssql.columnMapper, DropKeyMapper: sql.DropKeyMapper}
-		indexBuilder     = builder.Index{BufferFactory: ddlBufferFactory}
+		mssql            = mssql{Quote: builder.Quote{IDPrefix: "[", IDSuffix: "]", IDSuffixEscapeChar: "]", ValueQuote: "'", ValueQuoteEscapeChar: "'"}, options: options}
+		ddlBufferFactory = builder.BufferFactory{InlineValues: true, BoolTrueValue: "1", BoolFalseValue: "0", Quoter: mssql}
+		tableBuilder     = builder.Table{BufferFactory: ddlBufferFactory, ColumnMapper: mssql.columnMapper, DropKeyMapper: sql.DropKeyMapper, AlterColumnWriter: mssql.alterColumnWriter, AlterKeyWriter: mssql.alterKeyWriter, IdentityResetWriter: mssql.identityResetWriter, CommentWriter: mssql.commentWriter}
+		indexBuilder     = builder.Index{BufferFactory: ddlBufferFactory, Online: true, RenameIndexWriter: mssql.renameIndexWriter, FullTextIndexWriter: mssql.fullTextIndexWriter, CommentWriter: mssql.commentWriter}
 	)
 
 	return &sql.SQL{
-		TableBuilder: tableBuilder,
-		IndexBuilder: indexBuilder,
-		ErrorMapper:  mssql.errorMapper,
+		TableBuilder:      tableBuilder,
+		IndexBuilder:      indexBuilder,
+		ErrorMapper:       mssql.errorMapper,
+		Validator:         mssql.validate,
+		TransactionFilter: mssql.transactionFilter,
 	}
-}()
+}
+
+var PostgresSQL = NewPostgres(PostgresOptions{})
 
-var PostgresSQL = func() *sql.SQL {
+// NewPostgres returns PostgreSQL adapter configured using options.
+func NewPostgres(options PostgresOptions) *sql.SQL {
 	var (
-		postgres         = postgres{}
+		postgres         = postgres{options: options}
 		ddlBufferFactory = builder.BufferFactory{InlineValues: true, BoolTrueValue: "true", BoolFalseValue: "false", Quoter: postgres, ValueConverter: postgres}
-		tableBuilder     = builder.Table{BufferFactory: ddlBufferFactory, ColumnMapper: postgres.columnMapper, DropKeyMapper: sql.DropKeyMapper}
-		indexBuilder     = builder.Index{BufferFactory: ddlBufferFactory}
+		typeBuilder      = builder.Type{BufferFactory: ddlBufferFactory}
+		tableBuilder     = builder.Table{BufferFactory: ddlBufferFactory, ColumnMapper: postgres.columnMapper, DropKeyMapper: sql.DropKeyMapper, AlterColumnWriter: postgres.alterColumnWriter, AlterKeyWriter: postgres.alterKeyWriter, TypeWriter: postgres.typeWriter(typeBuilder), DropTableWriter: postgres.dropTableWriter, DropColumnWriter: postgres.dropColumnWriter, IdentityResetWriter: postgres.identityResetWriter, CommentWriter: postgres.commentWriter}
+		indexBuilder     = builder.Index{BufferFactory: ddlBufferFactory, FullTextIndexWriter: postgres.fullTextIndexWriter, CommentWriter: postgres.commentWriter}
 	)
 
 	return &sql.SQL{
-		TableBuilder: tableBuilder,
-		IndexBuilder: indexBuilder,
-		ErrorMapper:  postgres.errorMapper,
+		TableBuilder:      tableBuilder,
+		IndexBuilder:      indexBuilder,
+		TypeBuilder:       typeBuilder,
+		ErrorMapper:       postgres.errorMapper,
+		Validator:         postgres.validate,
+		Executor:          postgres.executor(indexBuilder),
+		TransactionFilter: postgres.transactionFilter,
 	}
-}()
+}
 
 func New(driver string) *sql.SQL {
 	switch driver {
@@ -0,0 +1,1325 @@
+package adapter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/dbm"
+	"github.com/jiyeyuran/dbm/adapter/sql"
+	"github.com/jiyeyuran/dbm/adapter/sql/builder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlterColumn(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.AlterTable("users", func(t *dbm.AlterTable) {
+		t.ChangeColumn("name", dbm.String, dbm.Limit(512), dbm.Required(true), dbm.Default("unnamed"))
+	})
+	schema.AlterTable("users", func(t *dbm.AlterTable) {
+		t.SetNotNull("age", dbm.Int)
+		t.DropNotNull("age", dbm.Int)
+		t.SetDefault("age", dbm.Int, 18)
+		t.DropDefault("age", dbm.Int)
+	})
+
+	tests := []struct {
+		adapter string
+		results []string
+	}{
+		{
+			adapter: "mysql",
+			results: []string{
+				"ALTER TABLE `users` MODIFY COLUMN `name` VARCHAR(512) NOT NULL DEFAULT 'unnamed';",
+				"ALTER TABLE `users` MODIFY COLUMN `age` INT NOT NULL;ALTER TABLE `users` MODIFY COLUMN `age` INT;ALTER TABLE `users` ALTER COLUMN `age` SET DEFAULT 18;ALTER TABLE `users` ALTER COLUMN `age` DROP DEFAULT;",
+			},
+		},
+		{
+			adapter: "postgres",
+			results: []string{
+				`ALTER TABLE "users" ALTER COLUMN "name" TYPE VARCHAR(512) USING "name"::VARCHAR(512), ALTER COLUMN "name" SET NOT NULL, ALTER COLUMN "name" SET DEFAULT 'unnamed';`,
+				`ALTER TABLE "users" ALTER COLUMN "age" SET NOT NULL;ALTER TABLE "users" ALTER COLUMN "age" DROP NOT NULL;ALTER TABLE "users" ALTER COLUMN "age" SET DEFAULT 18;ALTER TABLE "users" ALTER COLUMN "age" DROP DEFAULT;`,
+			},
+		},
+		{
+			adapter: "mssql",
+			results: []string{
+				"EXEC('DECLARE @name sysname; SELECT @name = dc.name FROM sys.default_constraints dc JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id WHERE dc.parent_object_id = OBJECT_ID(''users'') AND c.name = ''name''; IF @name IS NOT NULL EXEC(''ALTER TABLE [users] DROP CONSTRAINT '' + QUOTENAME(@name))');" +
+					"ALTER TABLE [users] ALTER COLUMN [name] NVARCHAR(512) NOT NULL;" +
+					"ALTER TABLE [users] ADD DEFAULT 'unnamed' FOR [name];",
+				"ALTER TABLE [users] ALTER COLUMN [age] INT NOT NULL;" +
+					"ALTER TABLE [users] ALTER COLUMN [age] INT NULL;" +
+					"EXEC('DECLARE @name sysname; SELECT @name = dc.name FROM sys.default_constraints dc JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id WHERE dc.parent_object_id = OBJECT_ID(''users'') AND c.name = ''age''; IF @name IS NOT NULL EXEC(''ALTER TABLE [users] DROP CONSTRAINT '' + QUOTENAME(@name))');" +
+					"ALTER TABLE [users] ADD DEFAULT 18 FOR [age];" +
+					"EXEC('DECLARE @name sysname; SELECT @name = dc.name FROM sys.default_constraints dc JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id WHERE dc.parent_object_id = OBJECT_ID(''users'') AND c.name = ''age''; IF @name IS NOT NULL EXEC(''ALTER TABLE [users] DROP CONSTRAINT '' + QUOTENAME(@name))');",
+			},
+		},
+		{
+			adapter: "sqlite3",
+			results: []string{"", ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.adapter, func(t *testing.T) {
+			for i, migration := range schema.Migrations {
+				assert.Equal(t, test.results[i], New(test.adapter).Build(migration))
+			}
+		})
+	}
+}
+
+func TestAlterColumn_restate(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.AlterTable("todos", func(t *dbm.AlterTable) {
+		t.SetNotNull("title", dbm.String, dbm.Limit(100), dbm.Default("untitled"), dbm.Comment("title of todo"))
+	})
+
+	assert.Equal(t, "ALTER TABLE `todos` MODIFY COLUMN `title` VARCHAR(100) NOT NULL DEFAULT 'untitled' COMMENT 'title of todo';", MYSQL.Build(schema.Migrations[0]))
+	assert.Equal(t, `ALTER TABLE "todos" ALTER COLUMN "title" SET NOT NULL;COMMENT ON COLUMN "todos"."title" IS 'title of todo';`, PostgresSQL.Build(schema.Migrations[0]))
+	// the default constraint is kept as nullability is changed without it.
+	assert.Equal(t, "ALTER TABLE [todos] ALTER COLUMN [title] NVARCHAR(100) NOT NULL;"+
+		"IF EXISTS (SELECT 1 FROM fn_listextendedproperty('MS_Description', 'SCHEMA', 'dbo', 'TABLE', 'todos', 'COLUMN', 'title'))"+
+		" EXEC sp_updateextendedproperty 'MS_Description', N'title of todo', 'SCHEMA', 'dbo', 'TABLE', 'todos', 'COLUMN', 'title'"+
+		" ELSE EXEC sp_addextendedproperty 'MS_Description', N'title of todo', 'SCHEMA', 'dbo', 'TABLE', 'todos', 'COLUMN', 'title';", MSSQL.Build(schema.Migrations[0]))
+}
+
+func TestAlterColumn_mysqlDefaultExpr(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.AlterTable("users", func(t *dbm.AlterTable) {
+		t.SetDefault("token", dbm.UUID, dbm.ExprUUID)
+		t.SetDefault("settings", dbm.JSON, `{"theme":"dark"}`)
+		t.SetDefault("birthday", dbm.Date, dbm.ExprNow)
+	})
+	schema.AlterTable("users", func(t *dbm.AlterTable) {
+		t.ChangeColumn("token", dbm.UUID, dbm.DefaultUUID())
+		t.ChangeColumn("settings", dbm.JSON, dbm.Default(`{"theme":"dark"}`))
+		t.ChangeColumn("birthday", dbm.Date, dbm.DefaultNow())
+	})
+
+	assert.Equal(t, "ALTER TABLE `users` ALTER COLUMN `token` SET DEFAULT (UUID());"+
+		"ALTER TABLE `users` ALTER COLUMN `settings` SET DEFAULT ('{\\\"theme\\\":\\\"dark\\\"}');"+
+		"ALTER TABLE `users` ALTER COLUMN `birthday` SET DEFAULT (CURRENT_DATE);", MYSQL.Build(schema.Migrations[0]))
+	assert.Equal(t, "ALTER TABLE `users` MODIFY COLUMN `token` CHAR(36) DEFAULT (UUID());"+
+		"ALTER TABLE `users` MODIFY COLUMN `settings` JSON DEFAULT ('{\\\"theme\\\":\\\"dark\\\"}');"+
+		"ALTER TABLE `users` MODIFY COLUMN `birthday` DATE DEFAULT (CURRENT_DATE);", MYSQL.Build(schema.Migrations[1]))
+}
+
+func TestAlterKey(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.AlterTable("users", func(t *dbm.AlterTable) {
+		t.DropForeignKey("users_role_id")
+		t.DropUnique("users_email")
+		t.DropPrimaryKey()
+		t.RenameConstraint("users_name", "users_full_name", dbm.UniqueKey)
+	})
+
+	tests := []struct {
+		adapter string
+		result  string
+	}{
+		{
+			adapter: "mysql",
+			result:  "ALTER TABLE `users` DROP FOREIGN KEY `users_role_id`;ALTER TABLE `users` DROP INDEX `users_email`;ALTER TABLE `users` DROP PRIMARY KEY;ALTER TABLE `users` RENAME INDEX `users_name` TO `users_full_name`;",
+		},
+		{
+			adapter: "postgres",
+			result: `ALTER TABLE "users" DROP CONSTRAINT "users_role_id";ALTER TABLE "users" DROP CONSTRAINT "users_email";` +
+				`DO $$BEGIN EXECUTE 'ALTER TABLE "users" DROP CONSTRAINT ' || quote_ident((SELECT conname FROM pg_constraint WHERE conrelid = '"users"'::regclass AND contype = 'p')); END$$;` +
+				`ALTER TABLE "users" RENAME CONSTRAINT "users_name" TO "users_full_name";`,
+		},
+		{
+			adapter: "mssql",
+			result: "ALTER TABLE [users] DROP CONSTRAINT [users_role_id];ALTER TABLE [users] DROP CONSTRAINT [users_email];" +
+				"EXEC('DECLARE @name sysname; SELECT @name = name FROM sys.key_constraints WHERE parent_object_id = OBJECT_ID(''users'') AND type = ''PK''; IF @name IS NOT NULL EXEC(''ALTER TABLE [users] DROP CONSTRAINT '' + QUOTENAME(@name))');" +
+				"EXEC sp_rename 'users_name', 'users_full_name', 'OBJECT';",
+		},
+		{
+			adapter: "sqlite3",
+			result:  "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.adapter, func(t *testing.T) {
+			assert.Equal(t, test.result, New(test.adapter).Build(schema.Migrations[0]))
+		})
+	}
+}
+
+func TestAlterKey_rename(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.RenameConstraint("users", "users_age", "users_age_check", dbm.CheckKey)
+
+	assert.EqualError(t, MYSQL.Validate(schema.Migrations[0]), "dbm: invalid definition of users.users_age: only unique keys can be renamed by MySQL")
+	assert.Nil(t, PostgresSQL.Validate(schema.Migrations[0]))
+	assert.EqualError(t, PostgresSQL.Validate(dbm.Table{Op: dbm.SchemaAlter, Name: "users", Definitions: []dbm.TableDefinition{
+		dbm.Key{Op: dbm.SchemaRename, Name: "users_age", Rename: "users_age_check"},
+	}}), "dbm: invalid definition of users.users_age: key type is required to rename a key")
+}
+
+func TestCheck(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("users", func(t *dbm.Table) {
+		t.Int("age")
+		t.Check("users_age", "age >= 18")
+	})
+	schema.AlterTable("users", func(t *dbm.AlterTable) {
+		t.Check("users_age_max", "age < 200")
+		t.DropCheck("users_age")
+	})
+
+	tests := []struct {
+		adapter string
+		results []string
+	}{
+		{
+			adapter: "mysql",
+			results: []string{
+				"CREATE TABLE `users` (`age` INT, CONSTRAINT `users_age` CHECK (age >= 18));",
+				"ALTER TABLE `users` ADD CONSTRAINT `users_age_max` CHECK (age < 200);ALTER TABLE `users` DROP CHECK `users_age`;",
+			},
+		},
+		{
+			adapter: "postgres",
+			results: []string{
+				`CREATE TABLE "users" ("age" INT, CONSTRAINT "users_age" CHECK (age >= 18));`,
+				`ALTER TABLE "users" ADD CONSTRAINT "users_age_max" CHECK (age < 200);ALTER TABLE "users" DROP CONSTRAINT "users_age";`,
+			},
+		},
+		{
+			adapter: "mssql",
+			results: []string{
+				"CREATE TABLE [users] ([age] INT, CONSTRAINT [users_age] CHECK (age >= 18));",
+				"ALTER TABLE [users] ADD CONSTRAINT [users_age_max] CHECK (age < 200);ALTER TABLE [users] DROP CONSTRAINT [users_age];",
+			},
+		},
+		{
+			adapter: "sqlite3",
+			results: []string{
+				`CREATE TABLE "users" ("age" INTEGER, CONSTRAINT "users_age" CHECK (age >= 18));`,
+				"",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.adapter, func(t *testing.T) {
+			for i, migration := range schema.Migrations {
+				assert.Equal(t, test.results[i], New(test.adapter).Build(migration))
+			}
+		})
+	}
+}
+
+func TestComment(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("users", func(t *dbm.Table) {
+		t.Int("age", dbm.Comment("age in years"))
+	}, dbm.Comment("registered users"))
+	schema.CreateIndex("users", "users_age", []string{"age"}, dbm.Comment("user's age"))
+	schema.AlterTable("users", func(t *dbm.AlterTable) {
+		t.ChangeColumn("age", dbm.Int, dbm.Comment("age in full years"))
+	}, dbm.Comment("active users"))
+
+	tests := []struct {
+		adapter string
+		results []string
+	}{
+		{
+			adapter: "mysql",
+			results: []string{
+				"CREATE TABLE `users` (`age` INT COMMENT 'age in years') COMMENT 'registered users';",
+				"CREATE INDEX `users_age` ON `users` (`age`) COMMENT 'user\\'s age';",
+				"ALTER TABLE `users` MODIFY COLUMN `age` INT COMMENT 'age in full years';ALTER TABLE `users` COMMENT 'active users';",
+			},
+		},
+		{
+			adapter: "postgres",
+			results: []string{
+				`CREATE TABLE "users" ("age" INT);COMMENT ON TABLE "users" IS 'registered users';COMMENT ON COLUMN "users"."age" IS 'age in years';`,
+				`CREATE INDEX "users_age" ON "users" ("age");COMMENT ON INDEX "users_age" IS 'user''s age';`,
+				`ALTER TABLE "users" ALTER COLUMN "age" TYPE INT USING "age"::INT, ALTER COLUMN "age" DROP NOT NULL, ALTER COLUMN "age" DROP DEFAULT;` +
+					`COMMENT ON TABLE "users" IS 'active users';COMMENT ON COLUMN "users"."age" IS 'age in full years';`,
+			},
+		},
+		{
+			adapter: "mssql",
+			results: []string{
+				"CREATE TABLE [users] ([age] INT);" +
+					"EXEC sp_addextendedproperty 'MS_Description', N'registered users', 'SCHEMA', 'dbo', 'TABLE', 'users';" +
+					"EXEC sp_addextendedproperty 'MS_Description', N'age in years', 'SCHEMA', 'dbo', 'TABLE', 'users', 'COLUMN', 'age';",
+				"CREATE INDEX [users_age] ON [users] ([age]);EXEC sp_addextendedproperty 'MS_Description', N'user''s age', 'SCHEMA', 'dbo', 'TABLE', 'users', 'INDEX', 'users_age';",
+				"EXEC('DECLARE @name sysname; SELECT @name = dc.name FROM sys.default_constraints dc JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id WHERE dc.parent_object_id = OBJECT_ID(''users'') AND c.name = ''age''; IF @name IS NOT NULL EXEC(''ALTER TABLE [users] DROP CONSTRAINT '' + QUOTENAME(@name))');" +
+					"ALTER TABLE [users] ALTER COLUMN [age] INT NULL;" +
+					"IF EXISTS (SELECT 1 FROM fn_listextendedproperty('MS_Description', 'SCHEMA', 'dbo', 'TABLE', 'users'))" +
+					" EXEC sp_updateextendedproperty 'MS_Description', N'active users', 'SCHEMA', 'dbo', 'TABLE', 'users'" +
+					" ELSE EXEC sp_addextendedproperty 'MS_Description', N'active users', 'SCHEMA', 'dbo', 'TABLE', 'users';" +
+					"IF EXISTS (SELECT 1 FROM fn_listextendedproperty('MS_Description', 'SCHEMA', 'dbo', 'TABLE', 'users', 'COLUMN', 'age'))" +
+					" EXEC sp_updateextendedproperty 'MS_Description', N'age in full years', 'SCHEMA', 'dbo', 'TABLE', 'users', 'COLUMN', 'age'" +
+					" ELSE EXEC sp_addextendedproperty 'MS_Description', N'age in full years', 'SCHEMA', 'dbo', 'TABLE', 'users', 'COLUMN', 'age';",
+			},
+		},
+		{
+			adapter: "sqlite3",
+			results: []string{
+				`CREATE TABLE "users" ("age" INTEGER);`,
+				`CREATE INDEX "users_age" ON "users" ("age");`,
+				"",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.adapter, func(t *testing.T) {
+			for i, migration := range schema.Migrations {
+				assert.Equal(t, test.results[i], New(test.adapter).Build(migration))
+			}
+		})
+	}
+}
+
+func TestDefaultExpr(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("users", func(t *dbm.Table) {
+		t.String("id", dbm.DefaultUUID())
+		t.DateTime("created_at", dbm.DefaultNow())
+		t.DateTime("updated_at", dbm.DefaultNow(), dbm.AutoUpdate(true))
+		t.Int("score", dbm.DefaultExpr("(1 + 1)"))
+	})
+	schema.AlterTable("users", func(t *dbm.AlterTable) {
+		t.SetDefault("created_at", dbm.DateTime, dbm.ExprNow)
+	})
+
+	tests := []struct {
+		adapter string
+		results []string
+	}{
+		{
+			adapter: "mysql",
+			results: []string{
+				"CREATE TABLE `users` (`id` VARCHAR(255) DEFAULT (UUID()), `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP, `updated_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, `score` INT DEFAULT (1 + 1));",
+				"ALTER TABLE `users` ALTER COLUMN `created_at` SET DEFAULT CURRENT_TIMESTAMP;",
+			},
+		},
+		{
+			adapter: "postgres",
+			results: []string{
+				`CREATE TABLE "users" ("id" VARCHAR(255) DEFAULT gen_random_uuid(), "created_at" TIMESTAMPTZ DEFAULT now(), "updated_at" TIMESTAMPTZ DEFAULT now(), "score" INT DEFAULT (1 + 1));`,
+				`ALTER TABLE "users" ALTER COLUMN "created_at" SET DEFAULT now();`,
+			},
+		},
+		{
+			adapter: "mssql",
+			results: []string{
+				"CREATE TABLE [users] ([id] NVARCHAR(255) DEFAULT NEWID(), [created_at] DATETIMEOFFSET DEFAULT SYSDATETIMEOFFSET(), [updated_at] DATETIMEOFFSET DEFAULT SYSDATETIMEOFFSET(), [score] INT DEFAULT (1 + 1));",
+				"EXEC('DECLARE @name sysname; SELECT @name = dc.name FROM sys.default_constraints dc JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id WHERE dc.parent_object_id = OBJECT_ID(''users'') AND c.name = ''created_at''; IF @name IS NOT NULL EXEC(''ALTER TABLE [users] DROP CONSTRAINT '' + QUOTENAME(@name))');" +
+					"ALTER TABLE [users] ADD DEFAULT SYSDATETIMEOFFSET() FOR [created_at];",
+			},
+		},
+		{
+			adapter: "sqlite3",
+			results: []string{
+				`CREATE TABLE "users" ("id" VARCHAR(255) DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))), "created_at" DATETIME DEFAULT CURRENT_TIMESTAMP, "updated_at" DATETIME DEFAULT CURRENT_TIMESTAMP, "score" INTEGER DEFAULT (1 + 1));`,
+				"",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.adapter, func(t *testing.T) {
+			for i, migration := range schema.Migrations {
+				assert.Equal(t, test.results[i], New(test.adapter).Build(migration))
+			}
+		})
+	}
+}
+
+func TestNormalizeDefault(t *testing.T) {
+	var (
+		schema    dbm.Schema
+		createdAt = time.Date(2023, 7, 22, 12, 30, 15, 123456789, time.UTC)
+	)
+
+	schema.CreateTable("users", func(t *dbm.Table) {
+		t.Bool("active", dbm.Default(1))
+		t.JSON("settings", dbm.Default(map[string]any{"theme": "dark"}))
+		t.DateTime("created_at", dbm.Precision(3), dbm.Default(createdAt))
+		t.Date("birthday", dbm.Default(createdAt))
+	})
+
+	tests := []struct {
+		adapter string
+		result  string
+	}{
+		{
+			adapter: "mysql",
+			result:  "CREATE TABLE `users` (`active` BOOL DEFAULT true, `settings` JSON DEFAULT ('{\\\"theme\\\":\\\"dark\\\"}'), `created_at` DATETIME(3) DEFAULT '2023-07-22 12:30:15.123', `birthday` DATE DEFAULT '2023-07-22');",
+		},
+		{
+			adapter: "postgres",
+			result:  `CREATE TABLE "users" ("active" BOOL DEFAULT true, "settings" JSONB DEFAULT '{"theme":"dark"}', "created_at" TIMESTAMPTZ(3) DEFAULT '2023-07-22 12:30:15.123+00:00', "birthday" DATE DEFAULT '2023-07-22');`,
+		},
+		{
+			adapter: "mssql",
+			result:  `CREATE TABLE [users] ([active] BIT DEFAULT 1, [settings] NVARCHAR(MAX) DEFAULT '{"theme":"dark"}', [created_at] DATETIMEOFFSET(3) DEFAULT '2023-07-22 12:30:15.123+00:00', [birthday] DATE DEFAULT '2023-07-22');`,
+		},
+		{
+			adapter: "sqlite3",
+			result:  `CREATE TABLE "users" ("active" BOOL DEFAULT 1, "settings" TEXT DEFAULT '{"theme":"dark"}', "created_at" DATETIME DEFAULT '2023-07-22 12:30:15.123', "birthday" DATE DEFAULT '2023-07-22');`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.adapter, func(t *testing.T) {
+			adapter := New(test.adapter)
+			assert.Nil(t, adapter.Validate(schema.Migrations[0]))
+			assert.Equal(t, test.result, adapter.Build(schema.Migrations[0]))
+		})
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.AlterTable("users", func(t *dbm.AlterTable) {
+		t.SetDefault("active", dbm.Bool, 1)
+		t.SetDefault("created_at", dbm.DateTime, dbm.ExprNow, dbm.Precision(3))
+	})
+
+	tests := []struct {
+		adapter string
+		result  string
+	}{
+		{
+			adapter: "mysql",
+			result:  "ALTER TABLE `users` ALTER COLUMN `active` SET DEFAULT true;ALTER TABLE `users` ALTER COLUMN `created_at` SET DEFAULT CURRENT_TIMESTAMP(3);",
+		},
+		{
+			adapter: "postgres",
+			result:  `ALTER TABLE "users" ALTER COLUMN "active" SET DEFAULT true;ALTER TABLE "users" ALTER COLUMN "created_at" SET DEFAULT now();`,
+		},
+		{
+			adapter: "mssql",
+			result: "EXEC('DECLARE @name sysname; SELECT @name = dc.name FROM sys.default_constraints dc JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id WHERE dc.parent_object_id = OBJECT_ID(''users'') AND c.name = ''active''; IF @name IS NOT NULL EXEC(''ALTER TABLE [users] DROP CONSTRAINT '' + QUOTENAME(@name))');" +
+				"ALTER TABLE [users] ADD DEFAULT 1 FOR [active];" +
+				"EXEC('DECLARE @name sysname; SELECT @name = dc.name FROM sys.default_constraints dc JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id WHERE dc.parent_object_id = OBJECT_ID(''users'') AND c.name = ''created_at''; IF @name IS NOT NULL EXEC(''ALTER TABLE [users] DROP CONSTRAINT '' + QUOTENAME(@name))');" +
+				"ALTER TABLE [users] ADD DEFAULT SYSDATETIMEOFFSET() FOR [created_at];",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.adapter, func(t *testing.T) {
+			adapter := New(test.adapter)
+			assert.Nil(t, adapter.Validate(schema.Migrations[0]))
+			assert.Equal(t, test.result, adapter.Build(schema.Migrations[0]))
+
+			assert.EqualError(t, adapter.Validate(dbm.Table{Op: dbm.SchemaAlter, Name: "users", Definitions: []dbm.TableDefinition{
+				dbm.Column{Op: dbm.SchemaAlter, Change: dbm.ChangeDefault, Name: "age", Type: dbm.Int, Default: "old"},
+			}}), `dbm: invalid definition of users.age: default "old" is not a valid int`)
+			assert.EqualError(t, adapter.Validate(dbm.Table{Op: dbm.SchemaAlter, Name: "users", Definitions: []dbm.TableDefinition{
+				dbm.Column{Op: dbm.SchemaAlter, Change: dbm.ChangeDefault, Name: "age", Default: 18},
+			}}), "dbm: invalid definition of users.age: column type is required to alter a column")
+		})
+	}
+}
+
+func TestTemporalPrecision(t *testing.T) {
+	var (
+		schema    dbm.Schema
+		createdAt = time.Date(2023, 7, 22, 12, 30, 15, 123456789, time.UTC)
+	)
+
+	schema.CreateTable("events", func(t *dbm.Table) {
+		t.DateTime("created_at", dbm.Default(createdAt))
+		t.DateTime("scheduled_at", dbm.Precision(2), dbm.WithTimezone(false), dbm.Default(createdAt))
+		t.DateTime("seen_at", dbm.WithTimezone(false), dbm.DefaultNow())
+		t.Time("starts_at", dbm.Precision(3), dbm.Default(createdAt))
+	})
+
+	tests := []struct {
+		adapter *sql.SQL
+		result  string
+	}{
+		{
+			adapter: MYSQL,
+			result:  "CREATE TABLE `events` (`created_at` DATETIME DEFAULT '2023-07-22 12:30:15', `scheduled_at` DATETIME(2) DEFAULT '2023-07-22 12:30:15.12', `seen_at` DATETIME DEFAULT CURRENT_TIMESTAMP, `starts_at` TIME(3) DEFAULT '12:30:15.123');",
+		},
+		{
+			adapter: PostgresSQL,
+			result:  `CREATE TABLE "events" ("created_at" TIMESTAMPTZ DEFAULT '2023-07-22 12:30:15.123456+00:00', "scheduled_at" TIMESTAMP(2) DEFAULT '2023-07-22 12:30:15.12', "seen_at" TIMESTAMP DEFAULT now(), "starts_at" TIME(3) DEFAULT '12:30:15.123');`,
+		},
+		{
+			adapter: MSSQL,
+			result:  "CREATE TABLE [events] ([created_at] DATETIMEOFFSET DEFAULT '2023-07-22 12:30:15.1234567+00:00', [scheduled_at] DATETIME2(2) DEFAULT '2023-07-22 12:30:15.12', [seen_at] DATETIME2 DEFAULT SYSDATETIME(), [starts_at] TIME(3) DEFAULT '12:30:15.123');",
+		},
+		{
+			adapter: SQLite3,
+			result:  `CREATE TABLE "events" ("created_at" DATETIME DEFAULT '2023-07-22 12:30:15', "scheduled_at" DATETIME DEFAULT '2023-07-22 12:30:15.12', "seen_at" DATETIME DEFAULT CURRENT_TIMESTAMP, "starts_at" TIME DEFAULT '12:30:15.123');`,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.result, test.adapter.Build(schema.Migrations[0]))
+	}
+
+	assert.EqualError(t, MSSQL.Validate(dbm.Table{Name: "events", Definitions: []dbm.TableDefinition{dbm.Column{Name: "created_at", Type: dbm.DateTime, Precision: 9}}}),
+		"dbm: invalid definition of events.created_at: precision 9 is not supported by MSSQL, fractional seconds are limited to 7 digits")
+	assert.EqualError(t, PostgresSQL.Validate(dbm.Table{Name: "events", Definitions: []dbm.TableDefinition{dbm.Column{Name: "starts_at", Type: dbm.Time, Precision: 7}}}),
+		"dbm: invalid definition of events.starts_at: precision 7 is not supported by PostgreSQL, fractional seconds are limited to 6 digits")
+	assert.Nil(t, PostgresSQL.Validate(dbm.Table{Name: "events", Definitions: []dbm.TableDefinition{dbm.Column{Name: "created_at", Type: dbm.DateTime, Precision: 6}}}))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		column dbm.Column
+		err    string
+	}{
+		{column: dbm.Column{Name: "active", Type: dbm.Bool, Default: 2}, err: "dbm: invalid definition of users.active: default 2 is not a valid bool"},
+		{column: dbm.Column{Name: "age", Type: dbm.Int, Default: "old"}, err: `dbm: invalid definition of users.age: default "old" is not a valid int`},
+		{column: dbm.Column{Name: "name", Type: dbm.String, Default: 1}, err: "dbm: invalid definition of users.name: default 1 is not a valid string"},
+		{column: dbm.Column{Name: "created_at", Type: dbm.DateTime, Default: true}, err: "dbm: invalid definition of users.created_at: default true is not a valid datetime"},
+		{column: dbm.Column{Name: "settings", Type: dbm.JSON, Default: map[string]any{"theme": "dark"}}},
+		{column: dbm.Column{Name: "age", Type: dbm.Int, Default: "18"}},
+		{column: dbm.Column{Name: "price", Type: dbm.Decimal, Default: 1.5}},
+		{column: dbm.Column{Name: "created_at", Type: dbm.DateTime, Default: dbm.ExprNow}},
+		{column: dbm.Column{Name: "count", Type: dbm.Int, Limit: 11}},
+		{column: dbm.Column{Name: "total", Type: dbm.BigInt, Limit: 20}},
+		{column: dbm.Column{Name: "count", Type: dbm.Int, Limit: -1}, err: "dbm: invalid definition of users.count: limit -1 is not a valid integer size"},
+		{column: dbm.Column{Name: "updated_at", Type: dbm.Date, AutoUpdate: true}, err: "dbm: invalid definition of users.updated_at: auto update requires a datetime column"},
+		{column: dbm.Column{Name: "status", Type: dbm.Enum}, err: "dbm: invalid definition of users.status: enum requires values"},
+		{column: dbm.Column{Name: "status", Type: dbm.Enum, Values: []string{"active"}, Default: "banned"}, err: `dbm: invalid definition of users.status: default "banned" is not a valid enum`},
+		{column: dbm.Column{Op: dbm.SchemaAlter, Name: "id", Type: dbm.ID}, err: "dbm: invalid definition of users.id: ID type can't be used to alter a column, use the type of its values such as Int, BigInt or UUID"},
+		{column: dbm.Column{Op: dbm.SchemaAlter, Name: "id", Type: dbm.BigID}, err: "dbm: invalid definition of users.id: BigID type can't be used to alter a column, use the type of its values such as Int, BigInt or UUID"},
+		{column: dbm.Column{Op: dbm.SchemaAlter, Name: "id", Type: dbm.UUIDID}, err: "dbm: invalid definition of users.id: UUIDID type can't be used to alter a column, use the type of its values such as Int, BigInt or UUID"},
+		{column: dbm.Column{Op: dbm.SchemaAlter, Name: "id", Type: dbm.BigInt}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.column.Name, func(t *testing.T) {
+			err := New("postgres").Validate(dbm.Table{Op: dbm.SchemaCreate, Name: "users", Definitions: []dbm.TableDefinition{test.column}})
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestUUID(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("users", func(t *dbm.Table) {
+		t.UUIDID("id")
+		t.UUID("token", dbm.Default("0b7f1e2c-52c4-4c8e-9d3a-6c1c7c0e5b1a"))
+	})
+
+	tests := []struct {
+		adapter *sql.SQL
+		result  string
+	}{
+		{
+			adapter: MYSQL,
+			result:  "CREATE TABLE `users` (`id` CHAR(36) PRIMARY KEY DEFAULT (UUID()), `token` CHAR(36) DEFAULT '0b7f1e2c-52c4-4c8e-9d3a-6c1c7c0e5b1a');",
+		},
+		{
+			adapter: NewMySQL(MySQLOptions{BinaryUUID: true}),
+			result:  "CREATE TABLE `users` (`id` BINARY(16) PRIMARY KEY DEFAULT (UUID_TO_BIN(UUID())), `token` BINARY(16) DEFAULT (UUID_TO_BIN('0b7f1e2c-52c4-4c8e-9d3a-6c1c7c0e5b1a')));",
+		},
+		{
+			adapter: PostgresSQL,
+			result:  `CREATE TABLE "users" ("id" UUID PRIMARY KEY DEFAULT gen_random_uuid(), "token" UUID DEFAULT '0b7f1e2c-52c4-4c8e-9d3a-6c1c7c0e5b1a');`,
+		},
+		{
+			adapter: MSSQL,
+			result:  "CREATE TABLE [users] ([id] UNIQUEIDENTIFIER PRIMARY KEY DEFAULT NEWID(), [token] UNIQUEIDENTIFIER DEFAULT '0b7f1e2c-52c4-4c8e-9d3a-6c1c7c0e5b1a');",
+		},
+		{
+			adapter: SQLite3,
+			result:  `CREATE TABLE "users" ("id" TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))), "token" TEXT DEFAULT '0b7f1e2c-52c4-4c8e-9d3a-6c1c7c0e5b1a');`,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.result, test.adapter.Build(schema.Migrations[0]))
+	}
+}
+
+func TestBinary(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("files", func(t *dbm.Table) {
+		t.Binary("hash", dbm.Limit(32), dbm.Default([]byte{0xca, 0xfe}))
+		t.Blob("content")
+		t.Blob("archive", dbm.Limit(1<<20))
+	})
+
+	tests := []struct {
+		adapter *sql.SQL
+		result  string
+	}{
+		{
+			adapter: MYSQL,
+			result:  "CREATE TABLE `files` (`hash` VARBINARY(32) DEFAULT X'cafe', `content` BLOB, `archive` LONGBLOB);",
+		},
+		{
+			adapter: PostgresSQL,
+			result:  `CREATE TABLE "files" ("hash" BYTEA DEFAULT '\xcafe'::bytea, "content" BYTEA, "archive" BYTEA);`,
+		},
+		{
+			adapter: MSSQL,
+			result:  "CREATE TABLE [files] ([hash] VARBINARY(32) DEFAULT 0xcafe, [content] VARBINARY(MAX), [archive] VARBINARY(MAX));",
+		},
+		{
+			adapter: SQLite3,
+			result:  `CREATE TABLE "files" ("hash" BLOB DEFAULT X'cafe', "content" BLOB, "archive" BLOB);`,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.result, test.adapter.Build(schema.Migrations[0]))
+	}
+}
+
+func TestEnum(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("orders", func(t *dbm.Table) {
+		t.Enum("status", []string{"pending", "paid"}, dbm.Default("pending"))
+	})
+	schema.AddColumn("orders", "source", dbm.Enum, dbm.Values{"web"})
+	schema.DropTable("orders")
+	schema.AddEnumValue("orders_status", "refunded")
+	schema.DropType("orders_status")
+
+	tests := []struct {
+		adapter *sql.SQL
+		result  []string
+	}{
+		{
+			adapter: MYSQL,
+			result: []string{
+				"CREATE TABLE `orders` (`status` ENUM('pending', 'paid') DEFAULT 'pending');",
+				"ALTER TABLE `orders` ADD COLUMN `source` ENUM('web');",
+				"DROP TABLE `orders`;",
+			},
+		},
+		{
+			adapter: PostgresSQL,
+			result: []string{
+				`CREATE TYPE "orders_status" AS ENUM ('pending', 'paid');COMMENT ON TYPE "orders_status" IS 'dbm enum of orders';CREATE TABLE "orders" ("status" "orders_status" DEFAULT 'pending');`,
+				`CREATE TYPE "orders_source" AS ENUM ('web');COMMENT ON TYPE "orders_source" IS 'dbm enum of orders';ALTER TABLE "orders" ADD COLUMN "source" "orders_source";`,
+				`DROP TABLE "orders";` +
+					`DO $$DECLARE types text; BEGIN SELECT string_agg(t.oid::regtype::text, ', ') INTO types FROM pg_type t WHERE t.typtype = 'e' AND obj_description(t.oid, 'pg_type') = 'dbm enum of orders'` +
+					` AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.refobjid = t.oid AND d.deptype = 'n'); IF types IS NOT NULL THEN EXECUTE 'DROP TYPE ' || types; END IF; END$$;`,
+				`ALTER TYPE "orders_status" ADD VALUE 'refunded';`,
+				`DROP TYPE "orders_status";`,
+			},
+		},
+		{
+			adapter: MSSQL,
+			result: []string{
+				"CREATE TABLE [orders] ([status] NVARCHAR(255) DEFAULT 'pending' CHECK ([status] IN ('pending', 'paid')));",
+				"ALTER TABLE [orders] ADD COLUMN [source] NVARCHAR(255) CHECK ([source] IN ('web'));",
+				"DROP TABLE [orders];",
+			},
+		},
+		{
+			adapter: SQLite3,
+			result: []string{
+				`CREATE TABLE "orders" ("status" VARCHAR(255) DEFAULT 'pending' CHECK ("status" IN ('pending', 'paid')));`,
+				`ALTER TABLE "orders" ADD COLUMN "source" VARCHAR(255) CHECK ("source" IN ('web'));`,
+				`DROP TABLE "orders";`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		for i, result := range test.result {
+			assert.Equal(t, result, test.adapter.Build(schema.Migrations[i]))
+		}
+	}
+
+	assert.EqualError(t, MYSQL.Validate(schema.Migrations[3]), "dbm: invalid definition of orders_status: types are not supported by the database")
+	assert.Nil(t, PostgresSQL.Validate(schema.Migrations[3]))
+}
+
+func TestEnum_optional(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTableIfNotExists("orders", func(t *dbm.Table) {
+		t.Enum("status", []string{"pending"})
+	})
+
+	assert.Equal(t, `DO $$BEGIN CREATE TYPE "orders_status" AS ENUM ('pending');COMMENT ON TYPE "orders_status" IS 'dbm enum of orders'; EXCEPTION WHEN duplicate_object THEN NULL; END$$;CREATE TABLE IF NOT EXISTS "orders" ("status" "orders_status");`, PostgresSQL.Build(schema.Migrations[0]))
+}
+
+func TestEnum_change(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.AlterTable("orders", func(t *dbm.AlterTable) {
+		t.ChangeColumn("status", dbm.Enum, dbm.Values{"pending", "paid"}, dbm.Required(true))
+	})
+	schema.DropColumn("orders", "status")
+
+	assert.Equal(t, `DO $$BEGIN CREATE TYPE "orders_status" AS ENUM ('pending', 'paid');COMMENT ON TYPE "orders_status" IS 'dbm enum of orders'; EXCEPTION WHEN duplicate_object THEN NULL; END$$;`+
+		`ALTER TYPE "orders_status" ADD VALUE IF NOT EXISTS 'pending';ALTER TYPE "orders_status" ADD VALUE IF NOT EXISTS 'paid';`+
+		`ALTER TABLE "orders" ALTER COLUMN "status" TYPE "orders_status" USING "status"::"orders_status", ALTER COLUMN "status" SET NOT NULL, ALTER COLUMN "status" DROP DEFAULT;`, PostgresSQL.Build(schema.Migrations[0]))
+	assert.False(t, PostgresSQL.Transactional(schema.Migrations[0]))
+
+	assert.Equal(t, `ALTER TABLE "orders" DROP COLUMN "status";`+
+		`DO $$DECLARE types text; BEGIN SELECT string_agg(t.oid::regtype::text, ', ') INTO types FROM pg_type t WHERE t.typtype = 'e' AND obj_description(t.oid, 'pg_type') = 'dbm enum of orders'`+
+		` AND t.oid = to_regtype('"orders_status"') AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.refobjid = t.oid AND d.deptype = 'n'); IF types IS NOT NULL THEN EXECUTE 'DROP TYPE ' || types; END IF; END$$;`, PostgresSQL.Build(schema.Migrations[1]))
+	assert.True(t, PostgresSQL.Transactional(schema.Migrations[1]))
+
+	assert.Equal(t, "EXEC('DECLARE @name sysname; SELECT @name = dc.name FROM sys.default_constraints dc JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id WHERE dc.parent_object_id = OBJECT_ID(''orders'') AND c.name = ''status''; IF @name IS NOT NULL EXEC(''ALTER TABLE [orders] DROP CONSTRAINT '' + QUOTENAME(@name))');"+
+		"EXEC('DECLARE @name sysname; SELECT @name = name FROM sys.check_constraints WHERE parent_object_id = OBJECT_ID(''orders'') AND definition LIKE ''([[]status]=%''; IF @name IS NOT NULL EXEC(''ALTER TABLE [orders] DROP CONSTRAINT '' + QUOTENAME(@name))');"+
+		"ALTER TABLE [orders] ALTER COLUMN [status] NVARCHAR(255) NOT NULL;"+
+		"ALTER TABLE [orders] ADD CHECK ([status] IN ('pending', 'paid'));", MSSQL.Build(schema.Migrations[0]))
+	assert.Equal(t, "ALTER TABLE [orders] DROP COLUMN [status];", MSSQL.Build(schema.Migrations[1]))
+}
+
+func TestSizedTypes(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("stats", func(t *dbm.Table) {
+		t.Int("tiny", dbm.Limit(1))
+		t.Int("medium", dbm.Limit(3))
+		t.Int("digits", dbm.Precision(12))
+		t.SmallInt("small")
+		t.Text("body", dbm.Limit(1<<20))
+		t.Text("dump", dbm.Limit(1<<30))
+		t.String("note", dbm.Limit(5000))
+		t.Char("code", dbm.Limit(2))
+		t.Int("width", dbm.Limit(11))
+	})
+
+	tests := []struct {
+		adapter *sql.SQL
+		result  string
+	}{
+		{
+			adapter: MYSQL,
+			result:  "CREATE TABLE `stats` (`tiny` TINYINT, `medium` MEDIUMINT, `digits` BIGINT, `small` SMALLINT, `body` MEDIUMTEXT, `dump` LONGTEXT, `note` VARCHAR(5000), `code` CHAR(2), `width` INT);",
+		},
+		{
+			adapter: PostgresSQL,
+			result:  `CREATE TABLE "stats" ("tiny" SMALLINT, "medium" INT, "digits" BIGINT, "small" SMALLINT, "body" TEXT, "dump" TEXT, "note" VARCHAR(5000), "code" CHAR(2), "width" INT);`,
+		},
+		{
+			adapter: MSSQL,
+			result:  "CREATE TABLE [stats] ([tiny] SMALLINT, [medium] INT, [digits] BIGINT, [small] SMALLINT, [body] NVARCHAR(MAX), [dump] NVARCHAR(MAX), [note] NVARCHAR(MAX), [code] NCHAR(2), [width] INT);",
+		},
+		{
+			adapter: SQLite3,
+			result:  `CREATE TABLE "stats" ("tiny" INTEGER, "medium" INTEGER, "digits" INTEGER, "small" INTEGER, "body" TEXT, "dump" TEXT, "note" VARCHAR(5000), "code" CHAR(2), "width" INTEGER);`,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Nil(t, test.adapter.Validate(schema.Migrations[0]))
+		assert.Equal(t, test.result, test.adapter.Build(schema.Migrations[0]))
+	}
+
+	schema.AddColumn("stats", "fixed", dbm.Char, dbm.Limit(5000))
+	assert.Equal(t, "ALTER TABLE [stats] ADD COLUMN [fixed] NVARCHAR(MAX);", MSSQL.Build(schema.Migrations[1]))
+}
+
+func TestUnsigned(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("stocks", func(t *dbm.Table) {
+		t.Int("quantity", dbm.Unsigned(true))
+		t.SmallInt("level", dbm.Unsigned(true), dbm.Options("NOT NULL"))
+		t.Int("flag", dbm.Limit(1), dbm.Unsigned(true))
+		t.Int("delta")
+	})
+
+	tests := []struct {
+		name    string
+		adapter *sql.SQL
+		result  string
+	}{
+		{
+			name:    "postgres",
+			adapter: PostgresSQL,
+			result:  `CREATE TABLE "stocks" ("quantity" INT, "level" SMALLINT NOT NULL, "flag" SMALLINT, "delta" INT);`,
+		},
+		{
+			name:    "postgres check",
+			adapter: NewPostgres(PostgresOptions{Unsigned: UnsignedCheck}),
+			result:  `CREATE TABLE "stocks" ("quantity" INT CHECK ("quantity" >= 0), "level" SMALLINT CHECK ("level" >= 0) NOT NULL, "flag" SMALLINT CHECK ("flag" >= 0), "delta" INT);`,
+		},
+		{
+			name:    "postgres widen",
+			adapter: NewPostgres(PostgresOptions{Unsigned: UnsignedWiden}),
+			result:  `CREATE TABLE "stocks" ("quantity" BIGINT CHECK ("quantity" >= 0), "level" INT CHECK ("level" >= 0) NOT NULL, "flag" SMALLINT CHECK ("flag" >= 0), "delta" INT);`,
+		},
+		{
+			name:    "mssql widen",
+			adapter: NewMSSQL(MSSQLOptions{Unsigned: UnsignedWiden}),
+			result:  "CREATE TABLE [stocks] ([quantity] BIGINT CHECK ([quantity] >= 0), [level] INT CHECK ([level] >= 0) NOT NULL, [flag] TINYINT, [delta] INT);",
+		},
+		{
+			name:    "sqlite3",
+			adapter: SQLite3,
+			result:  `CREATE TABLE "stocks" ("quantity" UNSIGNED INTEGER, "level" UNSIGNED INTEGER NOT NULL, "flag" UNSIGNED INTEGER, "delta" INTEGER);`,
+		},
+		{
+			name:    "sqlite3 check",
+			adapter: NewSQLite3(SQLite3Options{Unsigned: UnsignedCheck}),
+			result:  `CREATE TABLE "stocks" ("quantity" INTEGER CHECK ("quantity" >= 0), "level" INTEGER CHECK ("level" >= 0) NOT NULL, "flag" INTEGER CHECK ("flag" >= 0), "delta" INTEGER);`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.result, test.adapter.Build(schema.Migrations[0]))
+		})
+	}
+
+	total := dbm.Table{Name: "stocks", Definitions: []dbm.TableDefinition{dbm.Column{Name: "total", Type: dbm.BigInt, Unsigned: true}}}
+	assert.EqualError(t, NewPostgres(PostgresOptions{Unsigned: UnsignedWiden}).Validate(total), "dbm: invalid definition of stocks.total: unsigned 8 byte integer can't be widened, use UnsignedCheck or a decimal column")
+	assert.EqualError(t, NewMSSQL(MSSQLOptions{Unsigned: UnsignedWiden}).Validate(total), "dbm: invalid definition of stocks.total: unsigned 8 byte integer can't be widened, use UnsignedCheck or a decimal column")
+	assert.Nil(t, NewPostgres(PostgresOptions{Unsigned: UnsignedCheck}).Validate(total))
+}
+
+func TestUnsigned_alter(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.AlterTable("stocks", func(t *dbm.AlterTable) {
+		t.ChangeColumn("quantity", dbm.Int, dbm.Unsigned(true), dbm.Required(true))
+		t.SetNotNull("level", dbm.SmallInt, dbm.Unsigned(true))
+	})
+
+	tests := []struct {
+		name    string
+		adapter *sql.SQL
+		result  string
+	}{
+		{
+			name:    "postgres widen",
+			adapter: NewPostgres(PostgresOptions{Unsigned: UnsignedWiden}),
+			result: `ALTER TABLE "stocks" ALTER COLUMN "quantity" TYPE BIGINT USING "quantity"::BIGINT, ALTER COLUMN "quantity" SET NOT NULL, ALTER COLUMN "quantity" DROP DEFAULT;` +
+				`ALTER TABLE "stocks" DROP CONSTRAINT IF EXISTS "stocks_quantity_check", ADD CONSTRAINT "stocks_quantity_check" CHECK ("quantity" >= 0);` +
+				`ALTER TABLE "stocks" ALTER COLUMN "level" SET NOT NULL;`,
+		},
+		{
+			name:    "mssql widen",
+			adapter: NewMSSQL(MSSQLOptions{Unsigned: UnsignedWiden}),
+			result: "EXEC('DECLARE @name sysname; SELECT @name = dc.name FROM sys.default_constraints dc JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id WHERE dc.parent_object_id = OBJECT_ID(''stocks'') AND c.name = ''quantity''; IF @name IS NOT NULL EXEC(''ALTER TABLE [stocks] DROP CONSTRAINT '' + QUOTENAME(@name))');" +
+				"EXEC('DECLARE @name sysname; SELECT @name = name FROM sys.check_constraints WHERE parent_object_id = OBJECT_ID(''stocks'') AND definition = ''([quantity]>=(0))''; IF @name IS NOT NULL EXEC(''ALTER TABLE [stocks] DROP CONSTRAINT '' + QUOTENAME(@name))');" +
+				"ALTER TABLE [stocks] ALTER COLUMN [quantity] BIGINT NOT NULL;" +
+				"ALTER TABLE [stocks] ADD CHECK ([quantity] >= 0);" +
+				"EXEC('DECLARE @name sysname; SELECT @name = name FROM sys.check_constraints WHERE parent_object_id = OBJECT_ID(''stocks'') AND definition = ''([level]>=(0))''; IF @name IS NOT NULL EXEC(''ALTER TABLE [stocks] DROP CONSTRAINT '' + QUOTENAME(@name))');" +
+				"ALTER TABLE [stocks] ALTER COLUMN [level] INT NOT NULL;" +
+				"ALTER TABLE [stocks] ADD CHECK ([level] >= 0);",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Nil(t, test.adapter.Validate(schema.Migrations[0]))
+			assert.Equal(t, test.result, test.adapter.Build(schema.Migrations[0]))
+		})
+	}
+}
+
+func TestGenerated(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("users", func(t *dbm.Table) {
+		t.String("email")
+		t.String("email_lower", dbm.Generated("lower(email)", true))
+		t.Int("email_length", dbm.Generated("length(email)", false))
+	})
+
+	tests := []struct {
+		adapter *sql.SQL
+		result  string
+	}{
+		{
+			adapter: MYSQL,
+			result:  "CREATE TABLE `users` (`email` VARCHAR(255), `email_lower` VARCHAR(255) GENERATED ALWAYS AS (lower(email)) STORED, `email_length` INT GENERATED ALWAYS AS (length(email)) VIRTUAL);",
+		},
+		{
+			adapter: NewPostgres(PostgresOptions{Version: 18}),
+			result:  `CREATE TABLE "users" ("email" VARCHAR(255), "email_lower" VARCHAR(255) GENERATED ALWAYS AS (lower(email)) STORED, "email_length" INT GENERATED ALWAYS AS (length(email)) VIRTUAL);`,
+		},
+		{
+			adapter: MSSQL,
+			result:  "CREATE TABLE [users] ([email] NVARCHAR(255), [email_lower] AS (lower(email)) PERSISTED, [email_length] AS (length(email)));",
+		},
+		{
+			adapter: SQLite3,
+			result:  `CREATE TABLE "users" ("email" VARCHAR(255), "email_lower" VARCHAR(255) GENERATED ALWAYS AS (lower(email)) STORED, "email_length" INTEGER GENERATED ALWAYS AS (length(email)) VIRTUAL);`,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Nil(t, test.adapter.Validate(schema.Migrations[0]))
+		assert.Equal(t, test.result, test.adapter.Build(schema.Migrations[0]))
+	}
+}
+
+func TestGenerated_validate(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("users", func(t *dbm.Table) {
+		t.Int("email_length", dbm.Generated("length(email)", false))
+	})
+	schema.AddColumn("users", "email_lower", dbm.String, dbm.Generated("lower(email)", true))
+	schema.AddColumn("users", "email_upper", dbm.String, dbm.Generated("upper(email)", true), dbm.Default(""))
+
+	assert.EqualError(t, PostgresSQL.Validate(schema.Migrations[0]), "dbm: invalid definition of users.email_length: virtual generated columns require PostgreSQL 18")
+	assert.Nil(t, PostgresSQL.Validate(schema.Migrations[1]))
+	assert.EqualError(t, SQLite3.Validate(schema.Migrations[1]), "dbm: invalid definition of users.email_lower: stored generated columns can't be added to an existing table")
+	assert.EqualError(t, MYSQL.Validate(schema.Migrations[2]), "dbm: invalid definition of users.email_upper: generated column can't have a default")
+}
+
+func TestIdentity(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("invoices", func(t *dbm.Table) {
+		t.ID("id")
+		t.BigInt("number", dbm.Identity(true, 1000, 10))
+	})
+	schema.ResetIdentity("invoices", 5000)
+
+	tests := []struct {
+		name    string
+		adapter *sql.SQL
+		result  []string
+	}{
+		{
+			name:    "mysql",
+			adapter: MYSQL,
+			result: []string{
+				"CREATE TABLE `invoices` (`id` INT UNSIGNED AUTO_INCREMENT PRIMARY KEY, `number` BIGINT AUTO_INCREMENT);",
+				"ALTER TABLE `invoices` AUTO_INCREMENT = 5000;",
+			},
+		},
+		{
+			name:    "postgres",
+			adapter: PostgresSQL,
+			result: []string{
+				`CREATE TABLE "invoices" ("id" SERIAL NOT NULL PRIMARY KEY, "number" BIGINT GENERATED ALWAYS AS IDENTITY (START WITH 1000 INCREMENT BY 10));`,
+				`SELECT setval(pg_get_serial_sequence('"invoices"', attname), 5000, false) FROM pg_attribute WHERE attrelid = '"invoices"'::regclass AND attnum > 0 AND NOT attisdropped AND pg_get_serial_sequence('"invoices"', attname) IS NOT NULL;`,
+			},
+		},
+		{
+			name:    "postgres identity",
+			adapter: NewPostgres(PostgresOptions{Identity: true}),
+			result: []string{
+				`CREATE TABLE "invoices" ("id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY, "number" BIGINT GENERATED ALWAYS AS IDENTITY (START WITH 1000 INCREMENT BY 10));`,
+			},
+		},
+		{
+			name:    "mssql",
+			adapter: MSSQL,
+			result: []string{
+				"CREATE TABLE [invoices] ([id] INT NOT NULL IDENTITY(1,1) PRIMARY KEY, [number] BIGINT IDENTITY(1000,10));",
+				"IF EXISTS (SELECT 1 FROM sys.identity_columns WHERE object_id = OBJECT_ID('[invoices]') AND last_value IS NOT NULL) EXEC('DECLARE @seed BIGINT = 5000 - IDENT_INCR(''[invoices]''); DBCC CHECKIDENT (''[invoices]'', RESEED, @seed);') ELSE DBCC CHECKIDENT ('[invoices]', RESEED, 5000);",
+			},
+		},
+		{
+			name:    "sqlite3",
+			adapter: SQLite3,
+			result: []string{
+				`CREATE TABLE "invoices" ("id" INTEGER PRIMARY KEY, "number" BIGINT);`,
+				`DELETE FROM sqlite_sequence WHERE name = 'invoices';INSERT INTO sqlite_sequence (name, seq) VALUES ('invoices', 4999);`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i, result := range test.result {
+				assert.Equal(t, result, test.adapter.Build(schema.Migrations[i]))
+			}
+		})
+	}
+
+	assert.EqualError(t, MYSQL.Validate(schema.Migrations[0]), "dbm: invalid definition of invoices.number: identity start and increment are not supported, use AUTO_INCREMENT table option")
+	assert.EqualError(t, SQLite3.Validate(schema.Migrations[0]), "dbm: invalid definition of invoices.number: identity is only supported by ID columns without start and increment")
+	assert.Nil(t, PostgresSQL.Validate(schema.Migrations[0]))
+	assert.Nil(t, MSSQL.Validate(schema.Migrations[0]))
+	assert.EqualError(t, MSSQL.Validate(dbm.Table{Name: "invoices", Definitions: []dbm.TableDefinition{dbm.Column{Name: "code", Type: dbm.String, Identity: &dbm.ColumnIdentity{}}}}), "dbm: invalid definition of invoices.code: identity requires an integer column")
+}
+
+func TestIdentity_sqlite3(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("invoices", func(t *dbm.Table) {
+		t.ID("id", dbm.Identity(false, 0, 0))
+	})
+	schema.ResetIdentity("invoices", 5000)
+
+	assert.Nil(t, SQLite3.Validate(schema.Migrations[0]))
+	assert.Equal(t, `CREATE TABLE "invoices" ("id" INTEGER PRIMARY KEY AUTOINCREMENT);`, SQLite3.Build(schema.Migrations[0]))
+	assert.Equal(t, `DELETE FROM sqlite_sequence WHERE name = 'invoices';INSERT INTO sqlite_sequence (name, seq) VALUES ('invoices', 4999);`, SQLite3.Build(schema.Migrations[1]))
+}
+
+func TestCollation(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("users", func(t *dbm.Table) {
+		t.String("username", dbm.Collation("nocase"))
+	})
+	schema.CreateTable("posts", func(t *dbm.Table) {
+		t.Text("body", dbm.Charset("utf8mb4"), dbm.Collation("utf8mb4_bin"))
+	}, dbm.Charset("utf8mb4"), dbm.Collation("utf8mb4_unicode_ci"))
+	schema.ChangeColumn("users", "username", dbm.String, dbm.Collation("nocase"))
+
+	tests := []struct {
+		adapter *sql.SQL
+		result  []string
+	}{
+		{
+			adapter: MYSQL,
+			result: []string{
+				"CREATE TABLE `users` (`username` VARCHAR(255) COLLATE nocase);",
+				"CREATE TABLE `posts` (`body` TEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_bin) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;",
+				"ALTER TABLE `users` MODIFY COLUMN `username` VARCHAR(255) COLLATE nocase;",
+			},
+		},
+		{
+			adapter: PostgresSQL,
+			result: []string{
+				`CREATE TABLE "users" ("username" VARCHAR(255) COLLATE "nocase");`,
+				"",
+				`ALTER TABLE "users" ALTER COLUMN "username" TYPE VARCHAR(255) COLLATE "nocase" USING "username"::VARCHAR(255), ALTER COLUMN "username" DROP NOT NULL, ALTER COLUMN "username" DROP DEFAULT;`,
+			},
+		},
+		{
+			adapter: MSSQL,
+			result: []string{
+				"CREATE TABLE [users] ([username] NVARCHAR(255) COLLATE nocase);",
+				"",
+				"EXEC('DECLARE @name sysname; SELECT @name = dc.name FROM sys.default_constraints dc JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id WHERE dc.parent_object_id = OBJECT_ID(''users'') AND c.name = ''username''; IF @name IS NOT NULL EXEC(''ALTER TABLE [users] DROP CONSTRAINT '' + QUOTENAME(@name))');ALTER TABLE [users] ALTER COLUMN [username] NVARCHAR(255) COLLATE nocase NULL;",
+			},
+		},
+		{
+			adapter: SQLite3,
+			result: []string{
+				`CREATE TABLE "users" ("username" VARCHAR(255) COLLATE nocase);`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		for i, result := range test.result {
+			if result != "" {
+				assert.Equal(t, result, test.adapter.Build(schema.Migrations[i]))
+			}
+		}
+	}
+
+	assert.Nil(t, MYSQL.Validate(schema.Migrations[1]))
+	assert.EqualError(t, PostgresSQL.Validate(schema.Migrations[1]), "dbm: invalid definition of posts: table charset and collation are only supported by MySQL, use column collation instead")
+	assert.EqualError(t, MSSQL.Validate(dbm.Table{Name: "posts", Definitions: []dbm.TableDefinition{dbm.Column{Name: "body", Type: dbm.Text, Charset: "utf8"}}}), "dbm: invalid definition of posts.body: charset is only supported by MySQL, use collation instead")
+	assert.EqualError(t, SQLite3.Validate(dbm.Table{Name: "posts", Definitions: []dbm.TableDefinition{dbm.Column{Name: "body", Type: dbm.Text, Collation: "no case"}}}), `dbm: invalid definition of posts.body: collation "no case" is not a valid name`)
+	assert.EqualError(t, MYSQL.Validate(dbm.Table{Name: "posts", Definitions: []dbm.TableDefinition{dbm.Column{Name: "body", Type: dbm.Text, Charset: "latin1", Collation: "utf8mb4_bin"}}}), `dbm: invalid definition of posts.body: collation "utf8mb4_bin" is not valid for charset "latin1"`)
+	assert.EqualError(t, MYSQL.Validate(dbm.Table{Name: "posts", Definitions: []dbm.TableDefinition{dbm.Column{Name: "views", Type: dbm.Int, Collation: "utf8mb4_bin"}}}), "dbm: invalid definition of posts.views: charset and collation require a string column")
+}
+
+func TestIndexFeatures(t *testing.T) {
+	var (
+		partial    = dbm.Index{Op: dbm.SchemaCreate, Table: "users", Name: "users_email", Columns: []string{"email"}, Where: "deleted_at IS NULL"}
+		expression = dbm.Index{Op: dbm.SchemaCreate, Table: "users", Name: "users_email", Columns: []string{"lower(email)", "created_at"}, Expressions: []string{"lower(email)"}, Descending: []string{"created_at"}}
+		method     = dbm.Index{Op: dbm.SchemaCreate, Table: "users", Name: "users_tags", Columns: []string{"tags"}, Using: "gin"}
+		include    = dbm.Index{Op: dbm.SchemaCreate, Table: "users", Name: "users_email", Columns: []string{"email"}, Include: []string{"name"}}
+	)
+
+	tests := []struct {
+		adapter *sql.SQL
+		index   dbm.Index
+		result  string
+		err     string
+	}{
+		{adapter: PostgresSQL, index: partial, result: `CREATE INDEX "users_email" ON "users" ("email") WHERE deleted_at IS NULL;`},
+		{adapter: PostgresSQL, index: expression, result: `CREATE INDEX "users_email" ON "users" ((lower(email)), "created_at" DESC);`},
+		{adapter: PostgresSQL, index: method, result: `CREATE INDEX "users_tags" ON "users" USING gin ("tags");`},
+		{adapter: PostgresSQL, index: include, result: `CREATE INDEX "users_email" ON "users" ("email") INCLUDE ("name");`},
+		{adapter: MYSQL, index: partial, err: "dbm: invalid definition of users.users_email: partial indexes are not supported by MySQL"},
+		{adapter: MYSQL, index: expression, result: "CREATE INDEX `users_email` ON `users` ((lower(email)), `created_at` DESC);"},
+		{adapter: MYSQL, index: method, err: `dbm: invalid definition of users.users_tags: index method "gin" is not supported by MySQL`},
+		{adapter: MYSQL, index: dbm.Index{Op: dbm.SchemaCreate, Table: "users", Name: "users_email", Columns: []string{"email"}, Using: "HASH"}, result: "CREATE INDEX `users_email` ON `users` (`email`) USING HASH;"},
+		{adapter: MYSQL, index: include, err: "dbm: invalid definition of users.users_email: included columns are not supported by MySQL"},
+		{adapter: MSSQL, index: partial, result: "CREATE INDEX [users_email] ON [users] ([email]) WHERE deleted_at IS NULL;"},
+		{adapter: MSSQL, index: expression, err: "dbm: invalid definition of users.users_email: expression indexes are not supported by MSSQL"},
+		{adapter: MSSQL, index: method, err: "dbm: invalid definition of users.users_tags: index methods are not supported by MSSQL"},
+		{adapter: MSSQL, index: include, result: "CREATE INDEX [users_email] ON [users] ([email]) INCLUDE ([name]);"},
+		{adapter: SQLite3, index: partial, result: `CREATE INDEX "users_email" ON "users" ("email") WHERE deleted_at IS NULL;`},
+		{adapter: SQLite3, index: expression, result: `CREATE INDEX "users_email" ON "users" ((lower(email)), "created_at" DESC);`},
+		{adapter: SQLite3, index: method, err: "dbm: invalid definition of users.users_tags: index methods are not supported by SQLite"},
+		{adapter: SQLite3, index: include, err: "dbm: invalid definition of users.users_email: included columns are not supported by SQLite"},
+	}
+
+	for _, test := range tests {
+		if test.err != "" {
+			assert.EqualError(t, test.adapter.Validate(test.index), test.err)
+		} else {
+			assert.Nil(t, test.adapter.Validate(test.index))
+			assert.Equal(t, test.result, test.adapter.Build(test.index))
+		}
+	}
+
+	assert.EqualError(t, PostgresSQL.Validate(dbm.Index{Op: dbm.SchemaCreate, Table: "users", Name: "users_email", Columns: []string{"email"}, Descending: []string{"name"}}), `dbm: invalid definition of users.users_email: "name" is not an index column`)
+	assert.EqualError(t, PostgresSQL.Validate(dbm.Index{Op: dbm.SchemaCreate, Table: "users", Name: "users_email", Columns: []string{"email"}, Ascending: []string{"email"}, Descending: []string{"email"}}), `dbm: invalid definition of users.users_email: "email" can't be sorted in both ascending and descending order`)
+}
+
+func TestConcurrently(t *testing.T) {
+	var (
+		create = dbm.Index{Op: dbm.SchemaCreate, Table: "users", Name: "users_email", Columns: []string{"email"}, Where: "deleted_at IS NULL", Concurrently: true}
+		drop   = dbm.Index{Op: dbm.SchemaDrop, Table: "users", Name: "users_email", Optional: true, Concurrently: true}
+	)
+
+	assert.Nil(t, PostgresSQL.Validate(create))
+	assert.Equal(t, `CREATE INDEX CONCURRENTLY "users_email" ON "users" ("email") WHERE deleted_at IS NULL;`, PostgresSQL.Build(create))
+	assert.Equal(t, `DROP INDEX CONCURRENTLY IF EXISTS "users_email";`, PostgresSQL.Build(drop))
+	assert.Nil(t, MSSQL.Validate(create))
+	assert.Equal(t, "CREATE INDEX [users_email] ON [users] ([email]) WHERE deleted_at IS NULL WITH (ONLINE = ON);", MSSQL.Build(create))
+	assert.Equal(t, "DROP INDEX IF EXISTS [users_email];", MSSQL.Build(drop))
+	assert.EqualError(t, MYSQL.Validate(drop), "dbm: invalid definition of users.users_email: concurrent index builds are not supported by MySQL")
+	assert.EqualError(t, SQLite3.Validate(create), "dbm: invalid definition of users.users_email: concurrent index builds are not supported by SQLite")
+}
+
+func TestRenameIndex(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.RenameIndex("users", "users_email", "accounts_email")
+
+	assert.Equal(t, `ALTER INDEX "users_email" RENAME TO "accounts_email";`, PostgresSQL.Build(schema.Migrations[0]))
+	assert.Equal(t, "ALTER TABLE `users` RENAME INDEX `users_email` TO `accounts_email`;", MYSQL.Build(schema.Migrations[0]))
+	assert.Equal(t, "EXEC sp_rename 'users.users_email', 'accounts_email', 'INDEX';", MSSQL.Build(schema.Migrations[0]))
+}
+
+func TestRenameSQLite3Index(t *testing.T) {
+	tests := []struct {
+		sql    string
+		result string
+	}{
+		{
+			sql:    `CREATE INDEX "users_email" ON "users" ("email")`,
+			result: `CREATE INDEX "accounts_email" ON "users" ("email");`,
+		},
+		{
+			sql:    `CREATE UNIQUE INDEX IF NOT EXISTS [users_email] ON users (lower(email) DESC) WHERE deleted_at IS NULL`,
+			result: `CREATE UNIQUE INDEX IF NOT EXISTS "accounts_email" ON users (lower(email) DESC) WHERE deleted_at IS NULL;`,
+		},
+	}
+
+	for _, test := range tests {
+		result, ok := renameSQLite3Index(test.sql, `"accounts_email"`)
+		assert.True(t, ok)
+		assert.Equal(t, test.result, result)
+	}
+
+	_, ok := renameSQLite3Index(`CREATE TABLE "users" ("email" TEXT)`, `"accounts_email"`)
+	assert.False(t, ok)
+}
+
+func TestFullTextIndex(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateFullTextIndex("posts", "posts_search", []string{"title", "body"}, dbm.Language("english"))
+	schema.DropFullTextIndex("posts", "posts_search")
+	schema.CreateFullTextIndex("posts", "posts_search", []string{"body"})
+
+	tests := []struct {
+		adapter *sql.SQL
+		result  []string
+	}{
+		{
+			adapter: MYSQL,
+			result: []string{
+				"",
+				"DROP INDEX `posts_search` ON `posts`;",
+				"CREATE FULLTEXT INDEX `posts_search` ON `posts` (`body`);",
+			},
+		},
+		{
+			adapter: PostgresSQL,
+			result: []string{
+				`CREATE INDEX "posts_search" ON "posts" USING gin ((to_tsvector('english', coalesce("title", '') || ' ' || coalesce("body", ''))));`,
+				`DROP INDEX "posts_search";`,
+				`CREATE INDEX "posts_search" ON "posts" USING gin ((to_tsvector('simple', "body")));`,
+			},
+		},
+		{
+			adapter: MSSQL,
+			result: []string{
+				"IF NOT EXISTS (SELECT 1 FROM sys.fulltext_catalogs WHERE name = 'dbm_fulltext') CREATE FULLTEXT CATALOG [dbm_fulltext];" +
+					"EXEC('DECLARE @key sysname; SELECT @key = name FROM sys.indexes WHERE object_id = OBJECT_ID(''posts'') AND is_primary_key = 1; " +
+					"EXEC(''CREATE FULLTEXT INDEX ON [posts] ([title] LANGUAGE ''''english'''', [body] LANGUAGE ''''english'''') KEY INDEX '' + QUOTENAME(@key) + '' ON [dbm_fulltext]'')');",
+				"DROP FULLTEXT INDEX ON [posts];",
+			},
+		},
+		{
+			adapter: SQLite3,
+			result: []string{
+				"",
+				`DROP TRIGGER "posts_search_ai";DROP TRIGGER "posts_search_ad";DROP TRIGGER "posts_search_au";DROP TABLE "posts_search";`,
+				`CREATE VIRTUAL TABLE "posts_search" USING fts5("body", content='posts');` +
+					`INSERT INTO "posts_search" ("posts_search") VALUES ('rebuild');` +
+					`CREATE TRIGGER "posts_search_ai" AFTER INSERT ON "posts" BEGIN INSERT INTO "posts_search" (rowid, "body") VALUES (new.rowid, new."body"); END;` +
+					`CREATE TRIGGER "posts_search_ad" AFTER DELETE ON "posts" BEGIN INSERT INTO "posts_search" ("posts_search", rowid, "body") VALUES ('delete', old.rowid, old."body"); END;` +
+					`CREATE TRIGGER "posts_search_au" AFTER UPDATE ON "posts" BEGIN INSERT INTO "posts_search" ("posts_search", rowid, "body") VALUES ('delete', old.rowid, old."body"); INSERT INTO "posts_search" (rowid, "body") VALUES (new.rowid, new."body"); END;`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		for i, result := range test.result {
+			if result != "" {
+				assert.Nil(t, test.adapter.Validate(schema.Migrations[i]))
+				assert.Equal(t, result, test.adapter.Build(schema.Migrations[i]))
+			}
+		}
+	}
+
+	assert.EqualError(t, MYSQL.Validate(schema.Migrations[0]), "dbm: invalid definition of posts.posts_search: full-text language is not supported by MySQL")
+	assert.EqualError(t, SQLite3.Validate(schema.Migrations[0]), "dbm: invalid definition of posts.posts_search: full-text language is not supported by SQLite")
+	assert.EqualError(t, PostgresSQL.Validate(dbm.Index{Op: dbm.SchemaCreate, Table: "posts", Name: "posts_search", Columns: []string{"body"}, FullText: true, Catalog: "search"}), "dbm: invalid definition of posts.posts_search: full-text catalogs are not supported by PostgreSQL")
+	assert.EqualError(t, PostgresSQL.Validate(dbm.Index{Op: dbm.SchemaCreate, Table: "posts", Name: "posts_search", Columns: []string{"body"}, FullText: true, Unique: true}), "dbm: invalid definition of posts.posts_search: full-text index only supports columns, language and catalog")
+	assert.EqualError(t, MSSQL.Validate(dbm.Index{Op: dbm.SchemaCreate, Table: "posts", Name: "posts_body", Columns: []string{"body"}, Language: "english"}), "dbm: invalid definition of posts.posts_body: language and catalog require a full-text index")
+
+	for _, adapter := range []*sql.SQL{MYSQL, PostgresSQL, SQLite3} {
+		assert.True(t, adapter.Transactional(schema.Migrations[0]))
+	}
+	assert.False(t, MSSQL.Transactional(schema.Migrations[0]))
+	assert.True(t, MSSQL.Transactional(dbm.Index{Op: dbm.SchemaCreate, Table: "posts", Name: "posts_body", Columns: []string{"body"}}))
+}
+
+func TestForeignKeys(t *testing.T) {
+	var schema dbm.Schema
+
+	schema.CreateTable("orders", func(t *dbm.Table) {
+		t.Int("product_id")
+		t.Int("variant_id")
+		t.ForeignKeys([]string{"product_id", "variant_id"}, "variants", []string{"product_id", "id"},
+			dbm.Name("orders_variant_fk"), dbm.OnDelete("cascade"), dbm.InitiallyDeferred(true))
+	})
+
+	assert.Nil(t, PostgresSQL.Validate(schema.Migrations[0]))
+	assert.Equal(t, `CREATE TABLE "orders" ("product_id" INT, "variant_id" INT, CONSTRAINT "orders_variant_fk" FOREIGN KEY ("product_id", "variant_id") REFERENCES "variants" ("product_id", "id") ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED);`, PostgresSQL.Build(schema.Migrations[0]))
+	assert.Nil(t, SQLite3.Validate(schema.Migrations[0]))
+	assert.EqualError(t, MYSQL.Validate(schema.Migrations[0]), "dbm: invalid definition of orders.orders_variant_fk: deferrable foreign keys are not supported by MySQL")
+	assert.EqualError(t, MSSQL.Validate(schema.Migrations[0]), "dbm: invalid definition of orders.orders_variant_fk: deferrable foreign keys are not supported by MSSQL")
+
+	tests := []struct {
+		adapter *sql.SQL
+		key     dbm.Key
+		err     string
+	}{
+		{adapter: PostgresSQL, key: dbm.Key{Name: "fk", Type: dbm.ForeignKey, Columns: []string{"a", "b"}, Reference: dbm.ForeignKeyReference{Table: "t", Columns: []string{"a", "b"}, MatchFull: true}}},
+		{adapter: SQLite3, key: dbm.Key{Name: "fk", Type: dbm.ForeignKey, Columns: []string{"a", "b"}, Reference: dbm.ForeignKeyReference{Table: "t", Columns: []string{"a", "b"}, MatchFull: true}}, err: "MATCH FULL is not supported by SQLite"},
+		{adapter: PostgresSQL, key: dbm.Key{Name: "fk", Type: dbm.ForeignKey, Columns: []string{"a", "b"}, Reference: dbm.ForeignKeyReference{Table: "t", Columns: []string{"a"}}}, err: "foreign key has 2 columns but references 1 columns"},
+		{adapter: PostgresSQL, key: dbm.Key{Name: "fk", Type: dbm.ForeignKey, Columns: []string{"a"}, Reference: dbm.ForeignKeyReference{Table: "t", Columns: []string{"a"}, OnUpdate: "CASCADE; DROP TABLE t"}}, err: `"CASCADE; DROP TABLE t" is not a valid referential action, it must be one of CASCADE, SET NULL, SET DEFAULT, RESTRICT or NO ACTION`},
+		{adapter: MYSQL, key: dbm.Key{Name: "fk", Type: dbm.ForeignKey, Columns: []string{"a"}, Reference: dbm.ForeignKeyReference{Table: "t", Columns: []string{"a"}, OnDelete: "set  default"}}, err: "referential action SET DEFAULT is not supported by MySQL"},
+		{adapter: MSSQL, key: dbm.Key{Name: "fk", Type: dbm.ForeignKey, Columns: []string{"a"}, Reference: dbm.ForeignKeyReference{Table: "t", Columns: []string{"a"}, OnDelete: "restrict"}}, err: "referential action RESTRICT is not supported by MSSQL"},
+		{adapter: MSSQL, key: dbm.Key{Name: "fk", Type: dbm.ForeignKey, Columns: []string{"a"}, Reference: dbm.ForeignKeyReference{Table: "t", Columns: []string{"a"}, OnDelete: "no action"}}},
+	}
+
+	for _, test := range tests {
+		err := test.adapter.Validate(dbm.Table{Op: dbm.SchemaAlter, Name: "orders", Definitions: []dbm.TableDefinition{test.key}})
+		if test.err == "" {
+			assert.Nil(t, err)
+		} else {
+			assert.EqualError(t, err, "dbm: invalid definition of orders.fk: "+test.err)
+		}
+	}
+}
+
+func TestErrorMapper_Check(t *testing.T) {
+	tests := []struct {
+		adapter string
+		err     string
+	}{
+		{adapter: "mysql", err: "Error 3819 (HY000): Check constraint 'users_age' is violated."},
+		{adapter: "postgres", err: `pq: new row for relation "users" violates check constraint "users_age"`},
+		{adapter: "mssql", err: `mssql: The INSERT statement conflicted with the CHECK constraint "users_age". The conflict occurred in database "test", table "dbo.users", column 'age'.`},
+		{adapter: "sqlite3", err: "CHECK constraint failed: users_age"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.adapter, func(t *testing.T) {
+			var (
+				err    = errors.New(test.err)
+				mapped = New(test.adapter).ErrorMapper(err)
+			)
+
+			assert.Equal(t, dbm.ConstraintError{Key: "users_age", Type: dbm.CheckConstraint, Err: err}, mapped)
+			assert.ErrorIs(t, mapped, dbm.ErrCheckConstraint)
+		})
+	}
+}
+
+func TestSQLite3_Rebuild(t *testing.T) {
+	var (
+		tableBuilder = SQLite3.TableBuilder.(builder.Table)
+		current      = sqlite3Table{
+			Name: "users",
+			SQL: `CREATE TABLE "users" (
+				"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+				"name" VARCHAR(255) NOT NULL DEFAULT 'anonymous, unknown', -- display name
+				"age" INTEGER DEFAULT -1,
+				CONSTRAINT "users_name" UNIQUE ("name"),
+				CONSTRAINT "users_age" FOREIGN KEY ("age") REFERENCES "ages" ("id") ON DELETE CASCADE
+			)`,
+			Statements: []string{`CREATE INDEX "users_age" ON "users" ("age")`},
+		}
+		changes = []dbm.TableDefinition{
+			dbm.Column{Op: dbm.SchemaAlter, Name: "name", Type: dbm.Text},
+			dbm.Column{Op: dbm.SchemaAlter, Change: dbm.ChangeRequired, Name: "age", Required: true},
+			dbm.Column{Op: dbm.SchemaAlter, Change: dbm.ChangeDefault, Name: "age", Default: 18},
+			dbm.Key{Op: dbm.SchemaDrop, Type: dbm.UniqueKey, Name: "users_name"},
+			dbm.Key{Op: dbm.SchemaRename, Name: "users_age", Type: dbm.ForeignKey, Rename: "users_age_fk"},
+			dbm.Key{Op: dbm.SchemaCreate, Type: dbm.UniqueKey, Name: "users_name_age", Columns: []string{"name", "age"}},
+		}
+	)
+
+	statements, err := sqlite3{}.rebuild(tableBuilder, current, changes)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		`CREATE TABLE "dbm_rebuild_users" ("id" INTEGER PRIMARY KEY AUTOINCREMENT, "name" TEXT, "age" INTEGER NOT NULL DEFAULT 18, CONSTRAINT "users_age_fk" FOREIGN KEY ("age") REFERENCES "ages" ("id") ON DELETE CASCADE, CONSTRAINT "users_name_age" UNIQUE ("name", "age"));`,
+		`INSERT INTO "dbm_rebuild_users" ("id", "name", "age") SELECT "id", "name", "age" FROM "users";`,
+		`DROP TABLE "users";`,
+		`ALTER TABLE "dbm_rebuild_users" RENAME TO "users";`,
+		`CREATE INDEX "users_age" ON "users" ("age")`,
+	}, statements)
+
+	_, err = sqlite3{}.rebuild(tableBuilder, current, []dbm.TableDefinition{dbm.Key{Op: dbm.SchemaRename, Name: "users_age", Type: dbm.UniqueKey, Rename: "users_age_uq"}})
+	assert.EqualError(t, err, "dbm: constraint `users_age` is not unique in table `users`")
+}
+
+func TestSQLite3_RebuildPrimaryKey(t *testing.T) {
+	var (
+		tableBuilder = SQLite3.TableBuilder.(builder.Table)
+		drop         = []dbm.TableDefinition{dbm.Key{Op: dbm.SchemaDrop, Type: dbm.PrimaryKey}}
+	)
+
+	statements, err := sqlite3{}.rebuild(tableBuilder, sqlite3Table{Name: "users", SQL: `CREATE TABLE users(id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT) WITHOUT ROWID`}, drop)
+	assert.Nil(t, err)
+	assert.Equal(t, `CREATE TABLE "dbm_rebuild_users" (id INTEGER, name TEXT) WITHOUT ROWID;`, statements[0])
+
+	statements, err = sqlite3{}.rebuild(tableBuilder, sqlite3Table{Name: "users", SQL: `CREATE TABLE users(id INTEGER, name TEXT, PRIMARY KEY (id, name))`}, drop)
+	assert.Nil(t, err)
+	assert.Equal(t, `CREATE TABLE "dbm_rebuild_users" (id INTEGER, name TEXT);`, statements[0])
+
+	_, err = sqlite3{}.rebuild(tableBuilder, sqlite3Table{Name: "users", SQL: `CREATE TABLE users(id INTEGER)`}, drop)
+	assert.EqualError(t, err, "dbm: primary key not found in table `users`")
+
+	_, err = sqlite3{}.rebuild(tableBuilder, sqlite3Table{Name: "users", SQL: `CREATE TABLE users(id INTEGER)`}, []dbm.TableDefinition{dbm.Key{Op: dbm.SchemaDrop, Type: dbm.ForeignKey, Name: "fk"}})
+	assert.EqualError(t, err, "dbm: constraint `fk` not found in table `users`")
+}
@@ -0,0 +1,56 @@
+package adapter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jiyeyuran/dbm"
+)
+
+// validateTableCollation rejects charset and collation of tables, which are only supported by MySQL.
+func validateTableCollation(table dbm.Table) error {
+	if table.Charset != "" || table.Collation != "" {
+		return errors.New("table charset and collation are only supported by MySQL, use column collation instead")
+	}
+
+	return nil
+}
+
+// validateCollation rejects charset of columns, which is only supported by MySQL,
+// and collation names that can't be written unquoted when quoted is false.
+func validateCollation(column dbm.Column, quoted bool) error {
+	if column.Charset != "" {
+		return errors.New("charset is only supported by MySQL, use collation instead")
+	}
+
+	if !quoted && column.Collation != "" && !isCollationName(column.Collation) {
+		return fmt.Errorf("collation %q is not a valid name", column.Collation)
+	}
+
+	return nil
+}
+
+// validateMySQLCollation rejects invalid names and collations of another charset.
+func validateMySQLCollation(charset string, collation string) error {
+	switch {
+	case charset != "" && !isCollationName(charset):
+		return fmt.Errorf("charset %q is not a valid name", charset)
+	case collation != "" && !isCollationName(collation):
+		return fmt.Errorf("collation %q is not a valid name", collation)
+	case charset != "" && collation != "" && collation != charset && !strings.HasPrefix(collation, charset+"_"):
+		return fmt.Errorf("collation %q is not valid for charset %q", collation, charset)
+	}
+
+	return nil
+}
+
+func isCollationName(name string) bool {
+	for _, c := range name {
+		if !(c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+
+	return name != ""
+}
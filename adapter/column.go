@@ -0,0 +1,15 @@
+package adapter
+
+import (
+	"log"
+
+	"github.com/jiyeyuran/dbm"
+)
+
+// excludeAutoUpdate of column for databases that can only update a timestamp on every update using triggers.
+func excludeAutoUpdate(database string, column *dbm.Column) {
+	if column.AutoUpdate {
+		column.AutoUpdate = false
+		log.Printf("[DBM] %s adapter does not support auto update, it has been excluded", database)
+	}
+}
@@ -0,0 +1,35 @@
+package adapter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// feature of migration that may not be supported by a database,
+// name is the subject of the error message including its verb, such as "partial indexes are".
+type feature struct {
+	name      string
+	used      bool
+	supported bool
+}
+
+// validateFeatures returns an error for the first used feature that isn't supported by database.
+func validateFeatures(database string, features ...feature) error {
+	for _, f := range features {
+		if f.used && !f.supported {
+			return fmt.Errorf("%s not supported by %s", f.name, database)
+		}
+	}
+
+	return nil
+}
+
+func containsFold(values []string, value string) bool {
+	for i := range values {
+		if strings.EqualFold(values[i], value) {
+			return true
+		}
+	}
+
+	return false
+}
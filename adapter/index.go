@@ -0,0 +1,88 @@
+package adapter
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jiyeyuran/dbm"
+)
+
+// indexFeatures supported by a database, methods lists the accepted index methods, or any method when empty.
+type indexFeatures struct {
+	database     string
+	concurrently bool
+	where        bool
+	expressions  bool
+	include      bool
+	using        bool
+	methods      []string
+	language     bool
+	catalog      bool
+}
+
+// validate rejects index features that aren't supported by the database,
+// and sort orders or expressions that don't match any index column.
+func (f indexFeatures) validate(index dbm.Index) error {
+	if err := validateFeatures(f.database, feature{"concurrent index builds are", index.Concurrently, f.concurrently}); err != nil {
+		return err
+	}
+
+	if index.Op != dbm.SchemaCreate {
+		return nil
+	}
+
+	if err := f.validateFullText(index); err != nil {
+		return err
+	}
+
+	err := validateFeatures(f.database,
+		feature{"partial indexes are", index.Where != "", f.where},
+		feature{"expression indexes are", len(index.Expressions) > 0, f.expressions},
+		feature{"included columns are", len(index.Include) > 0, f.include},
+		feature{"index methods are", index.Using != "", f.using},
+		feature{fmt.Sprintf("index method %q is", index.Using), index.Using != "" && len(f.methods) > 0, containsFold(f.methods, index.Using)},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, columns := range [][]string{index.Expressions, index.Ascending, index.Descending} {
+		for _, col := range columns {
+			if !index.HasColumn(col) {
+				return fmt.Errorf("%q is not an index column", col)
+			}
+		}
+	}
+
+	for _, col := range index.Descending {
+		if index.IsAscending(col) {
+			return fmt.Errorf("%q can't be sorted in both ascending and descending order", col)
+		}
+	}
+
+	if len(index.Columns) == 0 {
+		return errors.New("index requires columns")
+	}
+
+	return nil
+}
+
+// validateFullText rejects options that full-text indexes don't support, and full-text options of other indexes.
+func (f indexFeatures) validateFullText(index dbm.Index) error {
+	if !index.FullText {
+		if index.Language != "" || index.Catalog != "" {
+			return errors.New("language and catalog require a full-text index")
+		}
+		return nil
+	}
+
+	if index.Unique || index.Concurrently || index.Where != "" || index.Using != "" ||
+		len(index.Expressions) > 0 || len(index.Ascending) > 0 || len(index.Descending) > 0 || len(index.Include) > 0 {
+		return errors.New("full-text index only supports columns, language and catalog")
+	}
+
+	return validateFeatures(f.database,
+		feature{"full-text language is", index.Language != "", f.language},
+		feature{"full-text catalogs are", index.Catalog != "", f.catalog},
+	)
+}
@@ -0,0 +1,34 @@
+package adapter
+
+import (
+	"github.com/jiyeyuran/dbm"
+	"github.com/jiyeyuran/dbm/adapter/sql"
+)
+
+// foreignKeyFeatures supported by a database, actions lists the referential actions rejected by the database.
+type foreignKeyFeatures struct {
+	database   string
+	deferrable bool
+	matchFull  bool
+	actions    []string
+}
+
+// validate rejects foreign key features that aren't supported by the database, along with keys that are not valid regardless of database.
+func (f foreignKeyFeatures) validate(table dbm.Table, key dbm.Key) error {
+	if err := sql.ValidateKey(table, key); err != nil || key.Op != dbm.SchemaCreate || key.Type != dbm.ForeignKey {
+		return err
+	}
+
+	var (
+		ref      = key.Reference
+		onDelete = sql.ReferentialAction(ref.OnDelete)
+		onUpdate = sql.ReferentialAction(ref.OnUpdate)
+	)
+
+	return validateFeatures(f.database,
+		feature{"MATCH FULL is", ref.MatchFull, f.matchFull},
+		feature{"deferrable foreign keys are", ref.Deferrable || ref.InitiallyDeferred, f.deferrable},
+		feature{"referential action " + onDelete + " is", onDelete != "", !containsFold(f.actions, onDelete)},
+		feature{"referential action " + onUpdate + " is", onUpdate != "", !containsFold(f.actions, onUpdate)},
+	)
+}
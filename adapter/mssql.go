@@ -1,14 +1,25 @@
 package adapter
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/jiyeyuran/dbm"
 	"github.com/jiyeyuran/dbm/adapter/sql"
+	"github.com/jiyeyuran/dbm/adapter/sql/builder"
 )
 
-type mssql struct{}
+// MSSQLOptions configures the MSSQL adapter.
+type MSSQLOptions struct {
+	// Unsigned chooses how unsigned integer columns are emulated, TINYINT is used for unsigned single byte integers regardless of it.
+	Unsigned UnsignedMode
+}
+
+type mssql struct {
+	builder.Quote
+	options MSSQLOptions
+}
 
 func (mssql) errorMapper(err error) error {
 	if err == nil {
@@ -36,9 +47,10 @@ func (mssql) errorMapper(err error) error {
 			Type: dbm.ForeignKeyConstraint,
 			Err:  err,
 		}
-	case strings.HasPrefix(msg, "mssql: The UPDATE statement conflicted with the CHECK"):
+	case strings.HasPrefix(msg, "mssql: The INSERT statement conflicted with the CHECK"),
+		strings.HasPrefix(msg, "mssql: The UPDATE statement conflicted with the CHECK"):
 		return dbm.ConstraintError{
-			Key:  sql.ExtractString(msg, "FOREIGN KEY constraint \"", "\""),
+			Key:  sql.ExtractString(msg, "CHECK constraint \"", "\". The conflict"),
 			Type: dbm.CheckConstraint,
 			Err:  err,
 		}
@@ -48,24 +60,45 @@ func (mssql) errorMapper(err error) error {
 }
 
 // columnMapper function.
-func (mssql) columnMapper(column *dbm.Column) (string, int, int) {
+func (ms mssql) columnMapper(column *dbm.Column) (string, int, int) {
 	var (
-		typ        string
-		m, n       int
-		timeLayout = "2006-01-02 15:04:05"
+		typ  string
+		m, n int
 	)
 
+	excludeAutoUpdate("MSSQL", column)
+
+	if sql.IntegerSize(*column) > 1 {
+		emulateUnsigned(column, ms.options.Unsigned, ms.ID)
+	}
+
+	switch column.Default {
+	case dbm.ExprNow:
+		if column.Type == dbm.DateTime && !column.WithoutTimezone {
+			column.Default = dbm.Expr("SYSDATETIMEOFFSET()")
+		} else {
+			column.Default = dbm.Expr("SYSDATETIME()")
+		}
+	case dbm.ExprUUID:
+		column.Default = dbm.Expr("NEWID()")
+	}
+
+	sql.HexDefault(column, "0x", "")
+
 	switch column.Type {
 	case dbm.ID:
-		typ = "INT NOT NULL IDENTITY(1,1)"
+		typ = "INT NOT NULL" + ms.identity(*column)
 	case dbm.BigID:
-		typ = "BIGINT NOT NULL IDENTITY(1,1)"
+		typ = "BIGINT NOT NULL" + ms.identity(*column)
+	case dbm.UUID, dbm.UUIDID:
+		typ = "UNIQUEIDENTIFIER"
 	case dbm.Bool:
 		typ = "BIT"
-	case dbm.Int:
-		typ = "INT"
-	case dbm.BigInt:
-		typ = "BIGINT"
+	case dbm.SmallInt, dbm.Int, dbm.BigInt:
+		typ = mssqlIntegerType(*column)
+		if column.Identity != nil {
+			typ += ms.identity(*column)
+		}
 	case dbm.Float:
 		typ = "FLOAT"
 		m = column.Precision
@@ -73,31 +106,390 @@ func (mssql) columnMapper(column *dbm.Column) (string, int, int) {
 		typ = "DECIMAL"
 		m = column.Precision
 		n = column.Scale
-	case dbm.String:
+	case dbm.String, dbm.Enum:
 		typ = "NVARCHAR"
 		m = column.Limit
 		if m == 0 {
 			m = 255
 		} else if m > 4000 {
-			m = 4000
+			typ = "NVARCHAR(MAX)"
+			m = 0
+		}
+	case dbm.Char:
+		typ = "NCHAR"
+		m = column.Limit
+		if m > 4000 {
+			// fixed length is limited to 4000 characters.
+			typ = "NVARCHAR(MAX)"
+			m = 0
 		}
 	case dbm.Text, dbm.JSON:
 		typ = "NVARCHAR(MAX)"
+	case dbm.Binary:
+		typ = "VARBINARY"
+		m = column.Limit
+		if m == 0 {
+			m = 255
+		} else if m > 8000 {
+			typ = "VARBINARY(MAX)"
+			m = 0
+		}
+	case dbm.Blob:
+		typ = "VARBINARY(MAX)"
 	case dbm.Date:
 		typ = "DATE"
-		timeLayout = "2006-01-02"
 	case dbm.DateTime:
 		typ = "DATETIMEOFFSET"
+		if column.WithoutTimezone {
+			typ = "DATETIME2"
+		}
+		m = column.Precision
+		sql.TimeDefault(column, mssqlPrecision(*column), !column.WithoutTimezone)
 	case dbm.Time:
 		typ = "TIME"
-		timeLayout = "15:04:05"
+		m = column.Precision
+		sql.TimeDefault(column, mssqlPrecision(*column), false)
 	default:
 		typ = string(column.Type)
 	}
 
-	if t, ok := column.Default.(time.Time); ok {
-		column.Default = t.Format(timeLayout)
+	if column.Generated != "" {
+		// computed columns are declared without type.
+		typ, m, n = "AS ("+column.Generated+")", 0, 0
+		if column.Stored {
+			typ += " PERSISTED"
+		}
+		column.Generated = ""
 	}
 
+	// there's no unsigned attribute, it's only used to choose the type.
+	column.Unsigned = false
+	sql.NormalizeDefault(column)
+
 	return typ, m, n
 }
+
+func (m mssql) alterColumnWriter(t builder.Table, buffer *builder.Buffer, table dbm.Table, column dbm.Column) {
+	var (
+		unsigned = column.Change != dbm.ChangeDefault && sql.IntegerSize(column) > 1 && emulatesUnsigned(column, m.options.Unsigned)
+		check    = unsignedCheck(column, m.ID)
+		typ      = t.MapColumnType(&column)
+		values   = column.Change == dbm.ChangeDefinition && len(column.Values) > 0
+	)
+
+	// default constraints depend on the column and must be dropped before it can be altered.
+	if column.Change != dbm.ChangeRequired {
+		m.writeDropDefault(buffer, table, column)
+	}
+
+	// so does the check constraint of emulated unsigned column, which is added again once the column is altered.
+	if unsigned {
+		m.writeDropUnsignedCheck(buffer, table, column)
+	}
+
+	// values of enum column are replaced as the check constraint can't be altered.
+	if values {
+		m.writeDropValuesCheck(buffer, table, column)
+	}
+
+	if column.Change != dbm.ChangeDefault {
+		buffer.WriteString("ALTER TABLE ")
+		buffer.WriteEscape(table.Name)
+		buffer.WriteString(" ALTER COLUMN ")
+		buffer.WriteEscape(column.Name)
+		buffer.WriteByte(' ')
+		buffer.WriteString(typ)
+		t.WriteCollation(buffer, column.Charset, column.Collation)
+
+		if column.Required {
+			buffer.WriteString(" NOT NULL")
+		} else {
+			buffer.WriteString(" NULL")
+		}
+
+		t.WriteOptions(buffer, table.Options)
+		buffer.WriteByte(';')
+	}
+
+	if unsigned {
+		buffer.WriteString("ALTER TABLE ")
+		buffer.WriteEscape(table.Name)
+		buffer.WriteString(" ADD ")
+		buffer.WriteString(check)
+		t.WriteOptions(buffer, table.Options)
+		buffer.WriteByte(';')
+	}
+
+	if values {
+		buffer.WriteString("ALTER TABLE ")
+		buffer.WriteEscape(table.Name)
+		buffer.WriteString(" ADD ")
+		t.WriteValuesCheck(buffer, column)
+		t.WriteOptions(buffer, table.Options)
+		buffer.WriteByte(';')
+	}
+
+	if column.Change != dbm.ChangeRequired && column.Default != nil {
+		buffer.WriteString("ALTER TABLE ")
+		buffer.WriteEscape(table.Name)
+		buffer.WriteString(" ADD DEFAULT ")
+		t.WriteDefault(buffer, column.Default)
+		buffer.WriteString(" FOR ")
+		buffer.WriteEscape(column.Name)
+		t.WriteOptions(buffer, table.Options)
+		buffer.WriteByte(';')
+	}
+}
+
+// writeDropDefault drops the default constraint of a column by looking up its generated name.
+func (m mssql) writeDropDefault(buffer *builder.Buffer, table dbm.Table, column dbm.Column) {
+	m.writeDropConstraint(buffer, table, "SELECT @name = dc.name FROM sys.default_constraints dc"+
+		" JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id"+
+		" WHERE dc.parent_object_id = OBJECT_ID("+buffer.Quoter.Value(table.Name)+") AND c.name = "+buffer.Quoter.Value(column.Name))
+}
+
+// writeDropUnsignedCheck drops the check constraint of emulated unsigned column,
+// it's looked up by the definition normalized by MSSQL as it's either a column or table constraint.
+func (m mssql) writeDropUnsignedCheck(buffer *builder.Buffer, table dbm.Table, column dbm.Column) {
+	m.writeDropConstraint(buffer, table, "SELECT @name = name FROM sys.check_constraints"+
+		" WHERE parent_object_id = OBJECT_ID("+buffer.Quoter.Value(table.Name)+") AND definition = "+buffer.Quoter.Value("("+m.ID(column.Name)+">=(0))"))
+}
+
+// writeDropValuesCheck drops the check constraint of enum column values,
+// MSSQL stores IN as comparisons of the column joined by OR, such as ([status]='paid' OR [status]='pending').
+func (m mssql) writeDropValuesCheck(buffer *builder.Buffer, table dbm.Table, column dbm.Column) {
+	// brackets are escaped as they start a character range in LIKE patterns.
+	pattern := "(" + strings.ReplaceAll(m.ID(column.Name), "[", "[[]") + "=%"
+
+	m.writeDropConstraint(buffer, table, "SELECT @name = name FROM sys.check_constraints"+
+		" WHERE parent_object_id = OBJECT_ID("+buffer.Quoter.Value(table.Name)+") AND definition LIKE "+buffer.Quoter.Value(pattern))
+}
+
+// writeDropConstraint drops a constraint which name is assigned to @name by lookup,
+// the lookup runs in a nested batch so it can be repeated within a single query.
+func (mssql) writeDropConstraint(buffer *builder.Buffer, table dbm.Table, lookup string) {
+	var (
+		drop  = builder.Buffer{Quoter: buffer.Quoter}
+		batch = builder.Buffer{Quoter: buffer.Quoter}
+	)
+
+	drop.WriteString("ALTER TABLE ")
+	drop.WriteEscape(table.Name)
+	drop.WriteString(" DROP CONSTRAINT ")
+
+	batch.WriteString("DECLARE @name sysname; ")
+	batch.WriteString(lookup)
+	batch.WriteString("; IF @name IS NOT NULL EXEC(")
+	batch.WriteString(buffer.Quoter.Value(drop.String()))
+	batch.WriteString(" + QUOTENAME(@name))")
+
+	buffer.WriteString("EXEC(")
+	buffer.WriteString(buffer.Quoter.Value(batch.String()))
+	buffer.WriteString(");")
+}
+
+func (m mssql) alterKeyWriter(t builder.Table, buffer *builder.Buffer, table dbm.Table, key dbm.Key) bool {
+	switch {
+	case key.Op == dbm.SchemaRename:
+		buffer.WriteString("EXEC sp_rename ")
+		buffer.WriteString(buffer.Quoter.Value(key.Name))
+		buffer.WriteString(", ")
+		buffer.WriteString(buffer.Quoter.Value(key.Rename))
+		buffer.WriteString(", 'OBJECT';")
+		return true
+	case key.Op == dbm.SchemaDrop && key.Type == dbm.PrimaryKey && key.Name == "":
+		m.writeDropConstraint(buffer, table, "SELECT @name = name FROM sys.key_constraints"+
+			" WHERE parent_object_id = OBJECT_ID("+buffer.Quoter.Value(table.Name)+") AND type = 'PK'")
+		return true
+	}
+
+	return false
+}
+
+// fullTextIndexWriter creates the full-text index of table in its catalog, which is created when it doesn't exist.
+// The key of full-text index is the primary key index of table, its name is looked up in a nested batch.
+func (m mssql) fullTextIndexWriter(i builder.Index, buffer *builder.Buffer, index dbm.Index) bool {
+	exists := "EXISTS (SELECT 1 FROM sys.fulltext_indexes WHERE object_id = OBJECT_ID(" + buffer.Quoter.Value(index.Table) + "))"
+
+	if index.Op == dbm.SchemaDrop {
+		if index.Optional {
+			buffer.WriteString("IF " + exists + " ")
+		}
+		buffer.WriteString("DROP FULLTEXT INDEX ON ")
+		buffer.WriteEscape(index.Table)
+		buffer.WriteByte(';')
+		return true
+	}
+
+	var (
+		catalog = index.Catalog
+		create  = builder.Buffer{Quoter: buffer.Quoter}
+		batch   = builder.Buffer{Quoter: buffer.Quoter}
+	)
+
+	if catalog == "" {
+		catalog = "dbm_fulltext"
+	}
+
+	create.WriteString("CREATE FULLTEXT INDEX ON ")
+	create.WriteEscape(index.Table)
+	create.WriteString(" (")
+	for n, col := range index.Columns {
+		if n > 0 {
+			create.WriteString(", ")
+		}
+		create.WriteEscape(col)
+		if index.Language != "" {
+			create.WriteString(" LANGUAGE ")
+			create.WriteString(buffer.Quoter.Value(index.Language))
+		}
+	}
+	create.WriteString(") KEY INDEX ")
+
+	suffix := builder.Buffer{Quoter: buffer.Quoter}
+	suffix.WriteString(" ON ")
+	suffix.WriteEscape(catalog)
+	i.WriteOptions(&suffix, index.Options)
+
+	batch.WriteString("DECLARE @key sysname; SELECT @key = name FROM sys.indexes WHERE object_id = OBJECT_ID(")
+	batch.WriteString(buffer.Quoter.Value(index.Table))
+	batch.WriteString(") AND is_primary_key = 1; EXEC(")
+	batch.WriteString(buffer.Quoter.Value(create.String()))
+	batch.WriteString(" + QUOTENAME(@key) + ")
+	batch.WriteString(buffer.Quoter.Value(suffix.String()))
+	batch.WriteString(")")
+
+	buffer.WriteString("IF NOT EXISTS (SELECT 1 FROM sys.fulltext_catalogs WHERE name = ")
+	buffer.WriteString(buffer.Quoter.Value(catalog))
+	buffer.WriteString(") CREATE FULLTEXT CATALOG ")
+	buffer.WriteEscape(catalog)
+	buffer.WriteByte(';')
+
+	if index.Optional {
+		buffer.WriteString("IF NOT " + exists + " ")
+	}
+	buffer.WriteString("EXEC(")
+	buffer.WriteString(buffer.Quoter.Value(batch.String()))
+	buffer.WriteString(");")
+	return true
+}
+
+// renameIndexWriter renames index using sp_rename, the index is identified by its table.
+func (mssql) renameIndexWriter(buffer *builder.Buffer, index dbm.Index) {
+	buffer.WriteString("EXEC sp_rename ")
+	buffer.WriteString(buffer.Quoter.Value(index.Table + "." + index.Name))
+	buffer.WriteString(", ")
+	buffer.WriteString(buffer.Quoter.Value(index.Rename))
+	buffer.WriteString(", 'INDEX'")
+}
+
+// commentWriter stores comment as MS_Description extended property, the convention used by SQL Server tools.
+// The property is updated instead of added when it already exists on an altered table.
+func (mssql) commentWriter(buffer *builder.Buffer, comment builder.Comment) {
+	object := "'SCHEMA', 'dbo', 'TABLE', " + buffer.Quoter.Value(comment.Table)
+
+	switch {
+	case comment.Index != "":
+		object += ", 'INDEX', " + buffer.Quoter.Value(comment.Index)
+	case comment.Column != "":
+		object += ", 'COLUMN', " + buffer.Quoter.Value(comment.Column)
+	}
+
+	if comment.Replace {
+		buffer.WriteString("IF EXISTS (SELECT 1 FROM fn_listextendedproperty('MS_Description', " + object + "))")
+		buffer.WriteString(" EXEC sp_updateextendedproperty 'MS_Description', N" + buffer.Quoter.Value(comment.Text) + ", " + object + " ELSE ")
+	}
+
+	buffer.WriteString("EXEC sp_addextendedproperty 'MS_Description', N" + buffer.Quoter.Value(comment.Text) + ", " + object + ";")
+}
+
+// transactionFilter rejects full-text indexes, they can't be created or dropped in a transaction.
+func (mssql) transactionFilter(migration interface{}) bool {
+	index, ok := migration.(dbm.Index)
+	return !ok || !index.FullText
+}
+
+func (ms mssql) validate(migration interface{}) error {
+	if err := sql.ValidateIndex(migration, indexFeatures{database: "MSSQL", concurrently: true, where: true, include: true, language: true, catalog: true}.validate); err != nil {
+		return err
+	}
+
+	if err := sql.ValidateKeys(migration, foreignKeyFeatures{database: "MSSQL", actions: []string{"RESTRICT"}}.validate); err != nil {
+		return err
+	}
+
+	if err := sql.ValidateTable(migration, validateTableCollation); err != nil {
+		return err
+	}
+
+	return sql.ValidateColumns(migration, func(table dbm.Table, column dbm.Column) error {
+		if err := validateCollation(column, false); err != nil {
+			return err
+		}
+
+		if err := validateUnsigned(column, ms.options.Unsigned); err != nil {
+			return err
+		}
+
+		if (column.Type == dbm.DateTime || column.Type == dbm.Time) && column.Precision > 7 {
+			return fmt.Errorf("precision %d is not supported by MSSQL, fractional seconds are limited to 7 digits", column.Precision)
+		}
+
+		return sql.ValidateColumn(table, column)
+	})
+}
+
+// identity clause of column, starts at 1 and is incremented by 1 unless specified.
+func (mssql) identity(column dbm.Column) string {
+	var start, increment = 1, 1
+
+	if column.Identity != nil {
+		if column.Identity.Start != 0 {
+			start = column.Identity.Start
+		}
+		if column.Identity.Increment != 0 {
+			increment = column.Identity.Increment
+		}
+	}
+
+	return " IDENTITY(" + strconv.Itoa(start) + "," + strconv.Itoa(increment) + ")"
+}
+
+// identityResetWriter reseeds identity column, so the next generated value is reset value.
+// The next value of a table that never had rows is the reseed value itself, otherwise it's incremented by the increment of the column,
+// the reseed value is computed in a nested batch so it can be repeated within a single query.
+func (ms mssql) identityResetWriter(buffer *builder.Buffer, table dbm.Table, reset dbm.IdentityReset) {
+	var (
+		name  = ms.Value(ms.ID(table.Name))
+		value = strconv.Itoa(reset.Value)
+		batch = "DECLARE @seed BIGINT = " + value + " - IDENT_INCR(" + name + "); DBCC CHECKIDENT (" + name + ", RESEED, @seed);"
+	)
+
+	buffer.WriteString("IF EXISTS (SELECT 1 FROM sys.identity_columns WHERE object_id = OBJECT_ID(" + name + ") AND last_value IS NOT NULL)")
+	buffer.WriteString(" EXEC(" + ms.Value(batch) + ")")
+	buffer.WriteString(" ELSE DBCC CHECKIDENT (" + name + ", RESEED, " + value + ");")
+}
+
+// mssqlPrecision of time column, the default precision of MSSQL is 100 nanoseconds.
+func mssqlPrecision(column dbm.Column) int {
+	if column.Precision == 0 {
+		return 7
+	}
+
+	return column.Precision
+}
+
+// mssqlIntegerType uses the smallest integer type available that stores the size of column.
+// TINYINT is unsigned in MSSQL, it's only used for unsigned columns.
+func mssqlIntegerType(column dbm.Column) string {
+	switch size := sql.IntegerSize(column); {
+	case size <= 1 && column.Unsigned:
+		return "TINYINT"
+	case size <= 2:
+		return "SMALLINT"
+	case size <= 4:
+		return "INT"
+	default:
+		return "BIGINT"
+	}
+}
@@ -2,15 +2,25 @@ package adapter
 
 import (
 	"database/sql/driver"
-	"fmt"
+	"errors"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jiyeyuran/dbm"
 	"github.com/jiyeyuran/dbm/adapter/sql"
+	"github.com/jiyeyuran/dbm/adapter/sql/builder"
 )
 
-type mysql struct{}
+// MySQLOptions configures the MySQL adapter.
+type MySQLOptions struct {
+	// BinaryUUID stores UUID columns as BINARY(16) instead of CHAR(36).
+	BinaryUUID bool
+}
+
+type mysql struct {
+	options MySQLOptions
+}
 
 func (q mysql) ID(name string) string {
 	end := strings.IndexRune(name, 0)
@@ -98,7 +108,10 @@ func (mysql) errorMapper(err error) error {
 		errCodeIndex = 0
 	}
 
-	switch msg[:errCodeIndex] {
+	// newer drivers include sql state after error code, e.g. Error 3819 (HY000).
+	errCode, _, _ := strings.Cut(msg[:errCodeIndex], " (")
+
+	switch errCode {
 	case "Error 1062":
 		return dbm.ConstraintError{
 			Key:  sql.ExtractString(msg, "key '", "'"),
@@ -111,28 +124,216 @@ func (mysql) errorMapper(err error) error {
 			Type: dbm.ForeignKeyConstraint,
 			Err:  err,
 		}
+	case "Error 3819":
+		return dbm.ConstraintError{
+			Key:  sql.ExtractString(msg, "constraint '", "'"),
+			Type: dbm.CheckConstraint,
+			Err:  err,
+		}
 	default:
 		return err
 	}
 }
 
-func (mysql) columnMapper(column *dbm.Column) (string, int, int) {
+func (mysql) validate(migration interface{}) error {
+	if err := sql.ValidateIndex(migration, indexFeatures{database: "MySQL", expressions: true, using: true, methods: []string{"BTREE", "HASH"}}.validate); err != nil {
+		return err
+	}
+
+	err := sql.ValidateKeys(migration, func(table dbm.Table, key dbm.Key) error {
+		if key.Op == dbm.SchemaRename && key.Type != dbm.UniqueKey {
+			return errors.New("only unique keys can be renamed by MySQL")
+		}
+
+		return foreignKeyFeatures{database: "MySQL", actions: []string{"SET DEFAULT"}}.validate(table, key)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = sql.ValidateTable(migration, func(table dbm.Table) error {
+		return validateMySQLCollation(table.Charset, table.Collation)
+	})
+	if err != nil {
+		return err
+	}
+
+	return sql.ValidateColumns(migration, func(table dbm.Table, column dbm.Column) error {
+		if err := validateMySQLCollation(column.Charset, column.Collation); err != nil {
+			return err
+		}
+
+		if identity := column.Identity; identity != nil && (identity.Start > 1 || identity.Increment > 1) {
+			return errors.New("identity start and increment are not supported, use AUTO_INCREMENT table option")
+		}
+
+		return sql.ValidateColumn(table, column)
+	})
+}
+
+func (m mysql) columnMapper(column *dbm.Column) (string, int, int) {
+	var fsp, now string
+	if column.Precision > 0 {
+		// fractional seconds of the default must match the column.
+		fsp = "(" + strconv.Itoa(column.Precision) + ")"
+	}
+
+	switch column.Type {
+	case dbm.DateTime:
+		now = "CURRENT_TIMESTAMP" + fsp
+	case dbm.Time:
+		now = "CURRENT_TIME" + fsp
+	default:
+		now = "CURRENT_TIMESTAMP"
+	}
+
+	// expression defaults other than CURRENT_TIMESTAMP must be parenthesized.
+	switch column.Default {
+	case dbm.ExprNow:
+		switch column.Type {
+		case dbm.Date:
+			column.Default = dbm.Expr("(CURRENT_DATE)")
+		case dbm.Time:
+			column.Default = dbm.Expr("(" + now + ")")
+		default:
+			column.Default = dbm.Expr(now)
+		}
+	case dbm.ExprUUID:
+		if m.binaryUUID(column) {
+			column.Default = dbm.Expr("(UUID_TO_BIN(UUID()))")
+		} else {
+			column.Default = dbm.Expr("(UUID())")
+		}
+	}
+
+	if column.AutoUpdate {
+		column.AutoUpdate = false
+		column.Options = strings.TrimSpace("ON UPDATE " + now + " " + column.Options)
+	}
+
 	switch column.Type {
 	case dbm.JSON:
+		sql.NormalizeDefault(column)
+		if s, ok := column.Default.(string); ok {
+			// JSON columns only accept expression defaults.
+			column.Default = dbm.Expr("(" + m.Value(s) + ")")
+		}
 		return "JSON", 0, 0
 
+	case dbm.UUID, dbm.UUIDID:
+		if !m.binaryUUID(column) {
+			return "CHAR", 36, 0
+		}
+
+		if s, ok := column.Default.(string); ok {
+			column.Default = dbm.Expr("(UUID_TO_BIN(" + m.Value(s) + "))")
+		}
+		return "BINARY", 16, 0
+
 	case dbm.DateTime:
+		sql.NormalizeDefault(column)
 		return "DATETIME", column.Precision, 0
 
+	case dbm.Time:
+		sql.NormalizeDefault(column)
+		return "TIME", column.Precision, 0
+
+	case dbm.Enum:
+		values := make([]string, len(column.Values))
+		for i := range column.Values {
+			values[i] = m.Value(column.Values[i])
+		}
+		column.Values = nil
+		return "ENUM(" + strings.Join(values, ", ") + ")", 0, 0
+
+	case dbm.Blob:
+		sql.NormalizeDefault(column)
+		if column.Limit > 65535 {
+			return "LONGBLOB", 0, 0
+		}
+		return "BLOB", 0, 0
+
 	default:
 		return sql.ColumnMapper(column)
 	}
 }
 
+func (m mysql) binaryUUID(column *dbm.Column) bool {
+	return m.options.BinaryUUID && (column.Type == dbm.UUID || column.Type == dbm.UUIDID)
+}
+
 func (mysql) dropKeyMapper(typ dbm.KeyType) string {
-	if typ == dbm.ForeignKey {
+	switch typ {
+	case dbm.ForeignKey:
 		return "FOREIGN KEY"
+	case dbm.PrimaryKey:
+		return "PRIMARY KEY"
+	case dbm.UniqueKey:
+		return "INDEX"
+	case dbm.CheckKey:
+		return "CHECK"
+	default:
+		return "CONSTRAINT"
+	}
+}
+
+func (mysql) alterKeyWriter(t builder.Table, buffer *builder.Buffer, table dbm.Table, key dbm.Key) bool {
+	switch {
+	case key.Op == dbm.SchemaRename:
+		// only unique keys can be renamed, through their underlying index.
+		buffer.WriteString("ALTER TABLE ")
+		buffer.WriteEscape(table.Name)
+		buffer.WriteString(" RENAME INDEX ")
+		buffer.WriteEscape(key.Name)
+		buffer.WriteString(" TO ")
+		buffer.WriteEscape(key.Rename)
+	case key.Op == dbm.SchemaDrop && key.Type == dbm.PrimaryKey:
+		// there's only one primary key, its name is always PRIMARY.
+		buffer.WriteString("ALTER TABLE ")
+		buffer.WriteEscape(table.Name)
+		buffer.WriteString(" DROP PRIMARY KEY")
+	default:
+		return false
+	}
+
+	t.WriteOptions(buffer, table.Options)
+	buffer.WriteByte(';')
+	return true
+}
+
+func (mysql) renameIndexWriter(buffer *builder.Buffer, index dbm.Index) {
+	buffer.WriteString("ALTER TABLE ")
+	buffer.WriteEscape(index.Table)
+	buffer.WriteString(" RENAME INDEX ")
+	buffer.WriteEscape(index.Name)
+	buffer.WriteString(" TO ")
+	buffer.WriteEscape(index.Rename)
+}
+
+func (mysql) alterColumnWriter(t builder.Table, buffer *builder.Buffer, table dbm.Table, column dbm.Column) {
+	buffer.WriteString("ALTER TABLE ")
+	buffer.WriteEscape(table.Name)
+	buffer.WriteByte(' ')
+
+	switch column.Change {
+	case dbm.ChangeDefinition, dbm.ChangeRequired:
+		// nullability can only be changed by restating the column definition,
+		// a default or comment of the existing column is reset unless it's passed as option.
+		buffer.WriteString("MODIFY COLUMN ")
+		t.WriteColumn(buffer, column)
+	case dbm.ChangeDefault:
+		// mapping the type normalizes the default the same way as MODIFY COLUMN, including parenthesized expressions.
+		t.MapColumnType(&column)
+		buffer.WriteString("ALTER COLUMN ")
+		buffer.WriteEscape(column.Name)
+		if column.Default != nil {
+			buffer.WriteString(" SET DEFAULT ")
+			t.WriteDefault(buffer, column.Default)
+		} else {
+			buffer.WriteString(" DROP DEFAULT")
+		}
 	}
 
-	panic(fmt.Sprintf("drop key: unsupported key type `%s`", typ))
+	t.WriteOptions(buffer, table.Options)
+	buffer.WriteByte(';')
 }
@@ -1,15 +1,33 @@
 package adapter
 
 import (
+	"context"
+	dsql "database/sql"
 	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jiyeyuran/dbm"
 	"github.com/jiyeyuran/dbm/adapter/sql"
+	"github.com/jiyeyuran/dbm/adapter/sql/builder"
 )
 
-type postgres struct{}
+// PostgresOptions configures the PostgreSQL adapter.
+type PostgresOptions struct {
+	// Unsigned chooses how unsigned integer columns are emulated, they're signed by default.
+	Unsigned UnsignedMode
+	// Identity uses identity columns for ID and BigID instead of SERIAL and BIGSERIAL.
+	Identity bool
+	// Version is the major version of the server, features of newer versions are rejected when it's older.
+	Version int
+}
+
+type postgres struct {
+	options PostgresOptions
+}
 
 func (q postgres) ID(name string) string {
 	end := strings.IndexRune(name, 0)
@@ -82,36 +100,378 @@ func (postgres) errorMapper(err error) error {
 	}
 }
 
-func (postgres) columnMapper(column *dbm.Column) (string, int, int) {
+func (p postgres) columnMapper(column *dbm.Column) (string, int, int) {
 	var (
 		typ  string
 		m, n int
 	)
 
 	// postgres specific
+	excludeAutoUpdate("PostgreSQL", column)
+	emulateUnsigned(column, p.options.Unsigned, p.ID)
 	column.Unsigned = false
-	if column.Default == "" {
+	if column.Collation != "" {
+		column.Collation = p.ID(column.Collation)
+	}
+	switch column.Default {
+	case "":
 		column.Default = nil
+	case dbm.ExprNow:
+		column.Default = dbm.Expr("now()")
+	case dbm.ExprUUID:
+		column.Default = dbm.Expr("gen_random_uuid()")
 	}
 
+	sql.HexDefault(column, "'\\x", "'::bytea")
+
 	switch column.Type {
 	case dbm.ID:
 		typ = "SERIAL NOT NULL"
+		if identity := p.identity(*column); identity != "" {
+			typ = "INT" + identity
+		}
 	case dbm.BigID:
 		typ = "BIGSERIAL NOT NULL"
+		if identity := p.identity(*column); identity != "" {
+			typ = "BIGINT" + identity
+		}
 	case dbm.DateTime:
 		typ = "TIMESTAMPTZ"
-		if t, ok := column.Default.(time.Time); ok {
-			column.Default = sql.FormatTime(t, sql.TimeLayoutWithOffset)
+		if column.WithoutTimezone {
+			typ = "TIMESTAMP"
 		}
-	case dbm.Int, dbm.BigInt, dbm.Text:
-		column.Limit = 0
-		typ, m, n = sql.ColumnMapper(column)
+		m = column.Precision
+		sql.TimeDefault(column, postgresPrecision(*column), !column.WithoutTimezone)
+	case dbm.Time:
+		typ = "TIME"
+		m = column.Precision
+		sql.TimeDefault(column, postgresPrecision(*column), false)
+	case dbm.SmallInt, dbm.Int, dbm.BigInt:
+		typ = postgresIntegerType(*column) + p.identity(*column)
+	case dbm.Text:
+		typ = "TEXT"
 	case dbm.JSON:
 		typ = "JSONB"
+	case dbm.UUID, dbm.UUIDID:
+		typ = "UUID"
+	case dbm.Binary, dbm.Blob:
+		typ = "BYTEA"
+	case dbm.Enum:
+		// values are defined by the type created using typeWriter.
+		typ = p.ID(column.TypeName)
+		column.Values = nil
 	default:
 		typ, m, n = sql.ColumnMapper(column)
 	}
 
+	sql.NormalizeDefault(column)
+
 	return typ, m, n
 }
+
+func (p postgres) validate(migration interface{}) error {
+	if err := sql.ValidateIndex(migration, indexFeatures{database: "PostgreSQL", concurrently: true, where: true, expressions: true, include: true, using: true, language: true}.validate); err != nil {
+		return err
+	}
+
+	if err := sql.ValidateKeys(migration, foreignKeyFeatures{database: "PostgreSQL", deferrable: true, matchFull: true}.validate); err != nil {
+		return err
+	}
+
+	if err := sql.ValidateTable(migration, validateTableCollation); err != nil {
+		return err
+	}
+
+	return sql.ValidateColumns(migration, func(table dbm.Table, column dbm.Column) error {
+		if column.Generated != "" && !column.Stored && p.options.Version < 18 {
+			return errors.New("virtual generated columns require PostgreSQL 18")
+		}
+
+		if err := validateCollation(column, true); err != nil {
+			return err
+		}
+
+		if err := validateUnsigned(column, p.options.Unsigned); err != nil {
+			return err
+		}
+
+		if (column.Type == dbm.DateTime || column.Type == dbm.Time) && column.Precision > 6 {
+			return fmt.Errorf("precision %d is not supported by PostgreSQL, fractional seconds are limited to 6 digits", column.Precision)
+		}
+
+		return sql.ValidateColumn(table, column)
+	})
+}
+
+// identity clause of column, ID and BigID use identity when enabled by options.
+func (p postgres) identity(column dbm.Column) string {
+	var (
+		identity = column.Identity
+		clause   = " GENERATED BY DEFAULT AS IDENTITY"
+		sequence []string
+	)
+
+	if identity == nil {
+		if !p.options.Identity || (column.Type != dbm.ID && column.Type != dbm.BigID) {
+			return ""
+		}
+		identity = &dbm.ColumnIdentity{}
+	}
+
+	if identity.Always {
+		clause = " GENERATED ALWAYS AS IDENTITY"
+	}
+
+	if identity.Start != 0 {
+		sequence = append(sequence, "START WITH "+strconv.Itoa(identity.Start))
+	}
+
+	if identity.Increment != 0 {
+		sequence = append(sequence, "INCREMENT BY "+strconv.Itoa(identity.Increment))
+	}
+
+	if len(sequence) > 0 {
+		clause += " (" + strings.Join(sequence, " ") + ")"
+	}
+
+	return clause
+}
+
+// identityResetWriter sets the sequence of serial or identity column, so the next generated value is reset value.
+func (p postgres) identityResetWriter(buffer *builder.Buffer, table dbm.Table, reset dbm.IdentityReset) {
+	name := p.Value(p.ID(table.Name))
+
+	buffer.WriteString("SELECT setval(pg_get_serial_sequence(" + name + ", attname), " + strconv.Itoa(reset.Value) + ", false)")
+	buffer.WriteString(" FROM pg_attribute WHERE attrelid = " + name + "::regclass AND attnum > 0 AND NOT attisdropped")
+	buffer.WriteString(" AND pg_get_serial_sequence(" + name + ", attname) IS NOT NULL;")
+}
+
+// postgresPrecision of time column, the default precision of PostgreSQL is microseconds.
+func postgresPrecision(column dbm.Column) int {
+	if column.Precision == 0 {
+		return 6
+	}
+
+	return column.Precision
+}
+
+// postgresIntegerType uses the smallest integer type available that stores the size of column.
+func postgresIntegerType(column dbm.Column) string {
+	switch size := sql.IntegerSize(column); {
+	case size <= 2:
+		return "SMALLINT"
+	case size <= 4:
+		return "INT"
+	default:
+		return "BIGINT"
+	}
+}
+
+// typeWriter creates enum type of column before the table using it, the type is commented so it can be told apart from user defined types.
+// The type of a redefined column is kept when it already exists and its new values are added, values can't be removed from a type.
+func (postgres) typeWriter(typeBuilder builder.Type) builder.TypeWriter {
+	return func(buffer *builder.Buffer, table dbm.Table, column dbm.Column) {
+		if column.Type != dbm.Enum {
+			return
+		}
+
+		typ := dbm.Type{
+			Name:     column.TypeName,
+			Values:   column.Values,
+			Optional: table.Optional || column.Op == dbm.SchemaAlter,
+			Comment:  postgresEnumComment(table.Name),
+		}
+
+		typeBuilder.WriteCreateType(buffer, typ)
+		if column.Op == dbm.SchemaAlter {
+			typeBuilder.WriteAlterType(buffer, typ)
+		}
+	}
+}
+
+func postgresEnumComment(table string) string {
+	return "dbm enum of " + table
+}
+
+// dropTableWriter drops the table, then enum types created by typeWriter for its columns that are no longer used.
+func (p postgres) dropTableWriter(t builder.Table, buffer *builder.Buffer, table dbm.Table) bool {
+	buffer.WriteString("DROP TABLE ")
+	if table.Optional {
+		buffer.WriteString("IF EXISTS ")
+	}
+	buffer.WriteEscape(table.Name)
+	buffer.WriteByte(';')
+
+	p.writeDropEnumTypes(buffer, table, "")
+	return true
+}
+
+// dropColumnWriter drops the column, then the enum type created by typeWriter for it when it's no longer used.
+// Types are named <table>_<column> when created, they're only found by the current name of the column.
+func (p postgres) dropColumnWriter(t builder.Table, buffer *builder.Buffer, table dbm.Table, column dbm.Column) bool {
+	buffer.WriteString("ALTER TABLE ")
+	buffer.WriteEscape(table.Name)
+	buffer.WriteString(" DROP COLUMN ")
+	buffer.WriteEscape(column.Name)
+	t.WriteOptions(buffer, table.Options)
+	buffer.WriteByte(';')
+
+	p.writeDropEnumTypes(buffer, table, table.Name+"_"+column.Name)
+	return true
+}
+
+// writeDropEnumTypes drops unused enum types that typeWriter created for table, or only the type with name when it's not empty.
+func (p postgres) writeDropEnumTypes(buffer *builder.Buffer, table dbm.Table, name string) {
+	buffer.WriteString("DO $$DECLARE types text; BEGIN SELECT string_agg(t.oid::regtype::text, ', ') INTO types FROM pg_type t WHERE t.typtype = 'e' AND obj_description(t.oid, 'pg_type') = ")
+	buffer.WriteString(p.Value(postgresEnumComment(table.Name)))
+	if name != "" {
+		buffer.WriteString(" AND t.oid = to_regtype(" + p.Value(p.ID(name)) + ")")
+	}
+	buffer.WriteString(" AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.refobjid = t.oid AND d.deptype = 'n'); IF types IS NOT NULL THEN EXECUTE 'DROP TYPE ' || types; END IF; END$$;")
+}
+
+// transactionFilter rejects tables that redefine enum columns, values added to an existing type
+// can't be used in the same transaction and can't be added in a transaction before PostgreSQL 12.
+func (postgres) transactionFilter(migration interface{}) bool {
+	table, ok := migration.(dbm.Table)
+	if !ok {
+		return true
+	}
+
+	for _, def := range table.Definitions {
+		if column, ok := def.(dbm.Column); ok && column.Op == dbm.SchemaAlter && column.Change == dbm.ChangeDefinition && column.Type == dbm.Enum {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fullTextIndexWriter creates a GIN index over the text search document of columns, drops are regular index drops.
+// Queries must use the same to_tsvector expression for the index to be used.
+func (p postgres) fullTextIndexWriter(i builder.Index, buffer *builder.Buffer, index dbm.Index) bool {
+	if index.Op != dbm.SchemaCreate {
+		return false
+	}
+
+	language := index.Language
+	if language == "" {
+		language = "simple"
+	}
+
+	document := make([]string, len(index.Columns))
+	for n, col := range index.Columns {
+		document[n] = p.ID(col)
+		if len(index.Columns) > 1 {
+			document[n] = "coalesce(" + document[n] + ", '')"
+		}
+	}
+
+	index.FullText = false
+	index.Using = "gin"
+	index.Columns = []string{"to_tsvector(" + p.Value(language) + ", " + strings.Join(document, " || ' ' || ") + ")"}
+	index.Expressions = index.Columns
+
+	buffer.WriteString(i.Build(index))
+	return true
+}
+
+// alterColumnWriter writes the default ALTER COLUMN clauses, then the CHECK constraint of emulated unsigned column,
+// which isn't part of altered definitions. It replaces the constraint named by PostgreSQL when the column was created.
+func (p postgres) alterColumnWriter(t builder.Table, buffer *builder.Buffer, table dbm.Table, column dbm.Column) {
+	unsigned := column.Change == dbm.ChangeDefinition && emulatesUnsigned(column, p.options.Unsigned)
+
+	// the default clauses are written when there's no writer.
+	t.AlterColumnWriter = nil
+	t.WriteAlterColumn(buffer, table, column)
+
+	if !unsigned {
+		return
+	}
+
+	name := table.Name + "_" + column.Name + "_check"
+	buffer.WriteString("ALTER TABLE ")
+	buffer.WriteEscape(table.Name)
+	buffer.WriteString(" DROP CONSTRAINT IF EXISTS ")
+	buffer.WriteEscape(name)
+	buffer.WriteString(", ADD CONSTRAINT ")
+	buffer.WriteEscape(name)
+	buffer.WriteByte(' ')
+	buffer.WriteString(unsignedCheck(column, p.ID))
+	t.WriteOptions(buffer, table.Options)
+	buffer.WriteByte(';')
+}
+
+func (postgres) alterKeyWriter(t builder.Table, buffer *builder.Buffer, table dbm.Table, key dbm.Key) bool {
+	if key.Op != dbm.SchemaDrop || key.Type != dbm.PrimaryKey || key.Name != "" {
+		return false
+	}
+
+	// primary key constraint name is generated when not specified, look it up from the catalog.
+	drop := builder.Buffer{Quoter: buffer.Quoter}
+	drop.WriteString("ALTER TABLE ")
+	drop.WriteEscape(table.Name)
+	drop.WriteString(" DROP CONSTRAINT ")
+
+	buffer.WriteString("DO $$BEGIN EXECUTE ")
+	buffer.WriteString(buffer.Quoter.Value(drop.String()))
+	buffer.WriteString(" || quote_ident((SELECT conname FROM pg_constraint WHERE conrelid = ")
+	buffer.WriteString(buffer.Quoter.Value(buffer.Quoter.ID(table.Name)))
+	buffer.WriteString("::regclass AND contype = 'p')); END$$;")
+	return true
+}
+
+func (postgres) commentWriter(buffer *builder.Buffer, comment builder.Comment) {
+	switch {
+	case comment.Index != "":
+		buffer.WriteString("COMMENT ON INDEX ")
+		buffer.WriteEscape(comment.Index)
+	case comment.Column != "":
+		buffer.WriteString("COMMENT ON COLUMN ")
+		buffer.WriteEscape(comment.Table)
+		buffer.WriteByte('.')
+		buffer.WriteEscape(comment.Column)
+	default:
+		buffer.WriteString("COMMENT ON TABLE ")
+		buffer.WriteEscape(comment.Table)
+	}
+
+	buffer.WriteString(" IS ")
+	buffer.WriteString(buffer.Quoter.Value(comment.Text))
+	buffer.WriteByte(';')
+}
+
+// executor builds concurrent indexes, dropping the invalid index left behind by a failed build
+// before retrying it and right after the build fails.
+func (p postgres) executor(indexBuilder builder.Index) sql.Executor {
+	return func(ctx context.Context, db dbm.Database, migration interface{}) (bool, error) {
+		index, ok := migration.(dbm.Index)
+		if !ok || index.Op != dbm.SchemaCreate || !index.Concurrently {
+			return false, nil
+		}
+
+		if err := p.dropInvalidIndex(ctx, db, index.Name); err != nil {
+			return true, err
+		}
+
+		if _, err := db.ExecContext(ctx, indexBuilder.Build(index)); err != nil {
+			// the build error is more useful than a failed cleanup, which is retried on the next run anyway.
+			_ = p.dropInvalidIndex(ctx, db, index.Name)
+			return true, err
+		}
+
+		return true, nil
+	}
+}
+
+func (p postgres) dropInvalidIndex(ctx context.Context, db dbm.Database, name string) error {
+	var invalid bool
+	if err := queryRows(ctx, db, "SELECT NOT indisvalid FROM pg_index WHERE indexrelid = to_regclass("+p.Value(p.ID(name))+")", func(rows *dsql.Rows) error {
+		return rows.Scan(&invalid)
+	}); err != nil || !invalid {
+		return err
+	}
+
+	_, err := db.ExecContext(ctx, "DROP INDEX CONCURRENTLY IF EXISTS "+p.ID(name)+";")
+	return err
+}
@@ -9,3 +9,7 @@ type TableBuilder interface {
 type IndexBuilder interface {
 	Build(index dbm.Index) string
 }
+
+type TypeBuilder interface {
+	Build(typ dbm.Type) string
+}
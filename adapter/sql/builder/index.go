@@ -4,19 +4,43 @@ import (
 	"github.com/jiyeyuran/dbm"
 )
 
+// RenameIndexWriter writes statement that renames an index, ALTER INDEX RENAME TO is written when it's not defined.
+type RenameIndexWriter func(buffer *Buffer, index dbm.Index)
+
+// FullTextIndexWriter writes statements that create or drop a full-text index, returns false to use the default statement.
+type FullTextIndexWriter func(i Index, buffer *Buffer, index dbm.Index) bool
+
 // Index builder.
 type Index struct {
 	BufferFactory    BufferFactory
 	DropIndexOnTable bool
+	// UsingAfterColumns writes the index method after the column list instead of before it.
+	UsingAfterColumns bool
+	// Online writes concurrent index builds as WITH (ONLINE = ON) instead of CONCURRENTLY,
+	// concurrent drops are written as regular drops.
+	Online              bool
+	RenameIndexWriter   RenameIndexWriter
+	FullTextIndexWriter FullTextIndexWriter
+	CommentWriter       CommentWriter
 }
 
 // Build sql query for index.
 func (i Index) Build(index dbm.Index) string {
 	buffer := i.BufferFactory.Create()
 
+	if index.FullText && i.FullTextIndexWriter != nil && i.FullTextIndexWriter(i, &buffer, index) {
+		return buffer.String()
+	}
+
 	switch index.Op {
 	case dbm.SchemaCreate:
 		i.WriteCreateIndex(&buffer, index)
+	case dbm.SchemaRename:
+		if i.RenameIndexWriter != nil {
+			i.RenameIndexWriter(&buffer, index)
+		} else {
+			i.WriteRenameIndex(&buffer, index)
+		}
 	case dbm.SchemaDrop:
 		i.WriteDropIndex(&buffer, index)
 	}
@@ -24,6 +48,10 @@ func (i Index) Build(index dbm.Index) string {
 	i.WriteOptions(&buffer, index.Options)
 	buffer.WriteByte(';')
 
+	if index.Op == dbm.SchemaCreate && index.Comment != "" && i.CommentWriter != nil {
+		i.CommentWriter(&buffer, Comment{Table: index.Table, Index: index.Name, Text: index.Comment})
+	}
+
 	return buffer.String()
 }
 
@@ -33,8 +61,15 @@ func (i Index) WriteCreateIndex(buffer *Buffer, index dbm.Index) {
 	if index.Unique {
 		buffer.WriteString("UNIQUE ")
 	}
+	if index.FullText {
+		buffer.WriteString("FULLTEXT ")
+	}
 	buffer.WriteString("INDEX ")
 
+	if index.Concurrently && !i.Online {
+		buffer.WriteString("CONCURRENTLY ")
+	}
+
 	if index.Optional {
 		buffer.WriteString("IF NOT EXISTS ")
 	}
@@ -43,20 +78,89 @@ func (i Index) WriteCreateIndex(buffer *Buffer, index dbm.Index) {
 	buffer.WriteString(" ON ")
 	buffer.WriteEscape(index.Table)
 
+	if !i.UsingAfterColumns {
+		i.WriteUsing(buffer, index.Using)
+	}
+
 	buffer.WriteString(" (")
 	for n, col := range index.Columns {
 		if n > 0 {
 			buffer.WriteString(", ")
 		}
-		buffer.WriteEscape(col)
+
+		if index.IsExpression(col) {
+			buffer.WriteByte('(')
+			buffer.WriteString(col)
+			buffer.WriteByte(')')
+		} else {
+			buffer.WriteEscape(col)
+		}
+
+		switch {
+		case index.IsDescending(col):
+			buffer.WriteString(" DESC")
+		case index.IsAscending(col):
+			buffer.WriteString(" ASC")
+		}
 	}
 	buffer.WriteString(")")
+
+	if i.UsingAfterColumns {
+		i.WriteUsing(buffer, index.Using)
+	}
+
+	if len(index.Include) > 0 {
+		buffer.WriteString(" INCLUDE (")
+		for n, col := range index.Include {
+			if n > 0 {
+				buffer.WriteString(", ")
+			}
+			buffer.WriteEscape(col)
+		}
+		buffer.WriteString(")")
+	}
+
+	if index.Where != "" {
+		buffer.WriteString(" WHERE ")
+		buffer.WriteString(index.Where)
+	}
+
+	if index.Concurrently && i.Online {
+		buffer.WriteString(" WITH (ONLINE = ON)")
+	}
+
+	if i.CommentWriter == nil && index.Comment != "" {
+		buffer.WriteString(" COMMENT ")
+		buffer.WriteString(buffer.Quoter.Value(index.Comment))
+	}
+}
+
+// WriteUsing writes index method to buffer.
+func (i Index) WriteUsing(buffer *Buffer, using string) {
+	if using == "" {
+		return
+	}
+
+	buffer.WriteString(" USING ")
+	buffer.WriteString(using)
+}
+
+// WriteRenameIndex to buffer
+func (i Index) WriteRenameIndex(buffer *Buffer, index dbm.Index) {
+	buffer.WriteString("ALTER INDEX ")
+	buffer.WriteEscape(index.Name)
+	buffer.WriteString(" RENAME TO ")
+	buffer.WriteEscape(index.Rename)
 }
 
 // WriteDropIndex to buffer
 func (i Index) WriteDropIndex(buffer *Buffer, index dbm.Index) {
 	buffer.WriteString("DROP INDEX ")
 
+	if index.Concurrently && !i.Online {
+		buffer.WriteString("CONCURRENTLY ")
+	}
+
 	if index.Optional {
 		buffer.WriteString("IF EXISTS ")
 	}
@@ -69,6 +69,59 @@ func TestIndex_Build(t *testing.T) {
 				Options:  "COMMENT 'comment'",
 			},
 		},
+		{
+			result: "CREATE INDEX `index` ON `table` USING gin ((lower(column1)), `column2` DESC, `column3` ASC) INCLUDE (`column4`) WHERE column2 > 0;",
+			index: dbm.Index{
+				Op:          dbm.SchemaCreate,
+				Table:       "table",
+				Name:        "index",
+				Columns:     []string{"lower(column1)", "column2", "column3"},
+				Expressions: []string{"lower(column1)"},
+				Ascending:   []string{"column3"},
+				Descending:  []string{"column2"},
+				Using:       "gin",
+				Include:     []string{"column4"},
+				Where:       "column2 > 0",
+			},
+		},
+		{
+			result: "CREATE INDEX CONCURRENTLY `index` ON `table` (`column1`);",
+			index: dbm.Index{
+				Op:           dbm.SchemaCreate,
+				Table:        "table",
+				Name:         "index",
+				Columns:      []string{"column1"},
+				Concurrently: true,
+			},
+		},
+		{
+			result: "DROP INDEX CONCURRENTLY `index` ON `table`;",
+			index: dbm.Index{
+				Op:           dbm.SchemaDrop,
+				Name:         "index",
+				Table:        "table",
+				Concurrently: true,
+			},
+		},
+		{
+			result: "ALTER INDEX `index` RENAME TO `new_index`;",
+			index: dbm.Index{
+				Op:     dbm.SchemaRename,
+				Name:   "index",
+				Table:  "table",
+				Rename: "new_index",
+			},
+		},
+		{
+			result: "CREATE FULLTEXT INDEX `index` ON `table` (`column1`, `column2`);",
+			index: dbm.Index{
+				Op:       dbm.SchemaCreate,
+				Table:    "table",
+				Name:     "index",
+				Columns:  []string{"column1", "column2"},
+				FullText: true,
+			},
+		},
 		{
 			result: "DROP INDEX `index` ON `table`;",
 			index: dbm.Index{
@@ -5,18 +5,57 @@ import (
 	"strconv"
 
 	"github.com/jiyeyuran/dbm"
+	"github.com/jiyeyuran/dbm/adapter/sql"
 )
 
 type ColumnMapper func(*dbm.Column) (string, int, int)
 type DropKeyMapper func(dbm.KeyType) string
 type DefinitionFilter func(table dbm.Table, def dbm.TableDefinition) bool
+type AlterColumnWriter func(t Table, buffer *Buffer, table dbm.Table, column dbm.Column)
+
+// AlterKeyWriter writes statements that drop or rename a key, returns false to use the default statement.
+type AlterKeyWriter func(t Table, buffer *Buffer, table dbm.Table, key dbm.Key) bool
+
+// TypeWriter writes statements that create the database type of a column before the column is created or redefined.
+type TypeWriter func(buffer *Buffer, table dbm.Table, column dbm.Column)
+
+// DropTableWriter writes statements that drop a table, returns false to use the default statement.
+type DropTableWriter func(t Table, buffer *Buffer, table dbm.Table) bool
+
+// DropColumnWriter writes statements that drop a column, returns false to use the default statement.
+type DropColumnWriter func(t Table, buffer *Buffer, table dbm.Table, column dbm.Column) bool
+
+// IdentityResetWriter writes statements that set the next value of the identity column of table.
+type IdentityResetWriter func(buffer *Buffer, table dbm.Table, reset dbm.IdentityReset)
+
+// Comment of a table, column or index.
+// Column and Index are empty when commenting the table.
+type Comment struct {
+	Table  string
+	Column string
+	Index  string
+	Text   string
+	// Replace is true when the table is altered, so the comment may already exist.
+	Replace bool
+}
+
+// CommentWriter writes statement that sets a comment after the table or index is created or altered.
+// Comments are written inline using COMMENT clause when CommentWriter is not defined.
+type CommentWriter func(buffer *Buffer, comment Comment)
 
 // Table builder.
 type Table struct {
-	BufferFactory    BufferFactory
-	ColumnMapper     ColumnMapper
-	DropKeyMapper    DropKeyMapper
-	DefinitionFilter DefinitionFilter
+	BufferFactory       BufferFactory
+	ColumnMapper        ColumnMapper
+	DropKeyMapper       DropKeyMapper
+	DefinitionFilter    DefinitionFilter
+	AlterColumnWriter   AlterColumnWriter
+	AlterKeyWriter      AlterKeyWriter
+	TypeWriter          TypeWriter
+	DropTableWriter     DropTableWriter
+	DropColumnWriter    DropColumnWriter
+	IdentityResetWriter IdentityResetWriter
+	CommentWriter       CommentWriter
 }
 
 // Build SQL query for table creation and modification.
@@ -43,6 +82,7 @@ func (t Table) Build(table dbm.Table) string {
 func (t Table) WriteCreateTable(buffer *Buffer, table dbm.Table) {
 	defs := t.definitions(table)
 
+	t.WriteTypes(buffer, table, defs)
 	buffer.WriteString("CREATE TABLE ")
 
 	if table.Optional {
@@ -69,15 +109,45 @@ func (t Table) WriteCreateTable(buffer *Buffer, table dbm.Table) {
 
 		buffer.WriteByte(')')
 	}
+
+	t.WriteCollation(buffer, table.Charset, table.Collation)
+
+	if t.CommentWriter == nil && table.Comment != "" {
+		buffer.WriteString(" COMMENT ")
+		buffer.WriteString(buffer.Quoter.Value(table.Comment))
+	}
+
 	t.WriteOptions(buffer, table.Options)
 	buffer.WriteByte(';')
+
+	t.WriteComments(buffer, table, defs)
 }
 
 // WriteAlterTable query to buffer.
 func (t Table) WriteAlterTable(buffer *Buffer, table dbm.Table) {
 	defs := t.definitions(table)
 
+	t.WriteTypes(buffer, table, defs)
 	for _, def := range defs {
+		if column, ok := def.(dbm.Column); ok && column.Op == dbm.SchemaAlter {
+			t.WriteAlterColumn(buffer, table, column)
+			continue
+		}
+
+		if key, ok := def.(dbm.Key); ok && key.Op != dbm.SchemaCreate {
+			t.WriteAlterKey(buffer, table, key)
+			continue
+		}
+
+		if column, ok := def.(dbm.Column); ok && column.Op == dbm.SchemaDrop && t.DropColumnWriter != nil && t.DropColumnWriter(t, buffer, table, column) {
+			continue
+		}
+
+		if reset, ok := def.(dbm.IdentityReset); ok {
+			t.WriteIdentityReset(buffer, table, reset)
+			continue
+		}
+
 		buffer.WriteString("ALTER TABLE ")
 		buffer.WriteEscape(table.Name)
 		buffer.WriteByte(' ')
@@ -99,22 +169,175 @@ func (t Table) WriteAlterTable(buffer *Buffer, table dbm.Table) {
 				buffer.WriteEscape(v.Name)
 			}
 		case dbm.Key:
-			// TODO: Rename and Drop, PR welcomed.
-			switch v.Op {
-			case dbm.SchemaCreate:
-				buffer.WriteString("ADD ")
-				t.WriteKey(buffer, v)
-			case dbm.SchemaDrop:
-				buffer.WriteString("DROP ")
-				buffer.WriteString(t.DropKeyMapper(v.Type))
-				buffer.WriteString(" ")
-				buffer.WriteEscape(v.Name)
-			}
+			buffer.WriteString("ADD ")
+			t.WriteKey(buffer, v)
 		}
 
 		t.WriteOptions(buffer, table.Options)
 		buffer.WriteByte(';')
 	}
+
+	if table.Charset != "" || table.Collation != "" {
+		buffer.WriteString("ALTER TABLE ")
+		buffer.WriteEscape(table.Name)
+		t.WriteCollation(buffer, table.Charset, table.Collation)
+		buffer.WriteByte(';')
+	}
+
+	if t.CommentWriter == nil && table.Comment != "" {
+		buffer.WriteString("ALTER TABLE ")
+		buffer.WriteEscape(table.Name)
+		buffer.WriteString(" COMMENT ")
+		buffer.WriteString(buffer.Quoter.Value(table.Comment))
+		buffer.WriteByte(';')
+	}
+
+	t.WriteComments(buffer, table, defs)
+}
+
+// WriteTypes statements of created or redefined columns to buffer using TypeWriter.
+func (t Table) WriteTypes(buffer *Buffer, table dbm.Table, defs []dbm.TableDefinition) {
+	if t.TypeWriter == nil {
+		return
+	}
+
+	for _, def := range defs {
+		if column, ok := def.(dbm.Column); ok && (column.Op == dbm.SchemaCreate || column.Op == dbm.SchemaAlter && column.Change == dbm.ChangeDefinition) {
+			t.TypeWriter(buffer, table, column)
+		}
+	}
+}
+
+// WriteComments statements of table and its columns to buffer using CommentWriter.
+func (t Table) WriteComments(buffer *Buffer, table dbm.Table, defs []dbm.TableDefinition) {
+	if t.CommentWriter == nil {
+		return
+	}
+
+	replace := table.Op == dbm.SchemaAlter
+
+	if table.Comment != "" {
+		t.CommentWriter(buffer, Comment{Table: table.Name, Text: table.Comment, Replace: replace})
+	}
+
+	for _, def := range defs {
+		if column, ok := def.(dbm.Column); ok && column.Comment != "" && column.Op != dbm.SchemaDrop {
+			t.CommentWriter(buffer, Comment{Table: table.Name, Column: column.Name, Text: column.Comment, Replace: replace})
+		}
+	}
+}
+
+// WriteAlterColumn statements to buffer.
+// Uses AlterColumnWriter when defined, otherwise writes PostgreSQL compatible ALTER COLUMN clauses.
+func (t Table) WriteAlterColumn(buffer *Buffer, table dbm.Table, column dbm.Column) {
+	if t.AlterColumnWriter != nil {
+		t.AlterColumnWriter(t, buffer, table, column)
+		return
+	}
+
+	var (
+		typ = t.MapColumnType(&column)
+	)
+
+	buffer.WriteString("ALTER TABLE ")
+	buffer.WriteEscape(table.Name)
+	buffer.WriteByte(' ')
+
+	switch column.Change {
+	case dbm.ChangeDefinition:
+		buffer.WriteString("ALTER COLUMN ")
+		buffer.WriteEscape(column.Name)
+		buffer.WriteString(" TYPE ")
+		buffer.WriteString(typ)
+		t.WriteCollation(buffer, column.Charset, column.Collation)
+		buffer.WriteString(" USING ")
+		if column.Using != "" {
+			buffer.WriteString(column.Using)
+		} else {
+			buffer.WriteEscape(column.Name)
+			buffer.WriteString("::")
+			buffer.WriteString(typ)
+		}
+		buffer.WriteString(", ")
+		t.writeAlterColumnRequired(buffer, column)
+		buffer.WriteString(", ")
+		t.writeAlterColumnDefault(buffer, column)
+	case dbm.ChangeRequired:
+		t.writeAlterColumnRequired(buffer, column)
+	case dbm.ChangeDefault:
+		t.writeAlterColumnDefault(buffer, column)
+	}
+
+	t.WriteOptions(buffer, table.Options)
+	buffer.WriteByte(';')
+}
+
+func (t Table) writeAlterColumnRequired(buffer *Buffer, column dbm.Column) {
+	buffer.WriteString("ALTER COLUMN ")
+	buffer.WriteEscape(column.Name)
+
+	if column.Required {
+		buffer.WriteString(" SET NOT NULL")
+	} else {
+		buffer.WriteString(" DROP NOT NULL")
+	}
+}
+
+func (t Table) writeAlterColumnDefault(buffer *Buffer, column dbm.Column) {
+	buffer.WriteString("ALTER COLUMN ")
+	buffer.WriteEscape(column.Name)
+
+	if column.Default != nil {
+		buffer.WriteString(" SET DEFAULT ")
+		t.WriteDefault(buffer, column.Default)
+	} else {
+		buffer.WriteString(" DROP DEFAULT")
+	}
+}
+
+// WriteAlterKey statement that drops or renames a key to buffer.
+// Uses AlterKeyWriter when defined, otherwise drops the key using DropKeyMapper or renames the constraint.
+func (t Table) WriteAlterKey(buffer *Buffer, table dbm.Table, key dbm.Key) {
+	if t.AlterKeyWriter != nil && t.AlterKeyWriter(t, buffer, table, key) {
+		return
+	}
+
+	buffer.WriteString("ALTER TABLE ")
+	buffer.WriteEscape(table.Name)
+	buffer.WriteByte(' ')
+
+	switch key.Op {
+	case dbm.SchemaRename:
+		buffer.WriteString("RENAME CONSTRAINT ")
+		buffer.WriteEscape(key.Name)
+		buffer.WriteString(" TO ")
+		buffer.WriteEscape(key.Rename)
+	case dbm.SchemaDrop:
+		buffer.WriteString("DROP ")
+		buffer.WriteString(t.DropKeyMapper(key.Type))
+		if key.Name != "" {
+			buffer.WriteByte(' ')
+			buffer.WriteEscape(key.Name)
+		}
+	}
+
+	t.WriteOptions(buffer, table.Options)
+	buffer.WriteByte(';')
+}
+
+// WriteIdentityReset statement to buffer.
+// Uses IdentityResetWriter when defined, otherwise sets AUTO_INCREMENT of the table.
+func (t Table) WriteIdentityReset(buffer *Buffer, table dbm.Table, reset dbm.IdentityReset) {
+	if t.IdentityResetWriter != nil {
+		t.IdentityResetWriter(buffer, table, reset)
+		return
+	}
+
+	buffer.WriteString("ALTER TABLE ")
+	buffer.WriteEscape(table.Name)
+	buffer.WriteString(" AUTO_INCREMENT = ")
+	buffer.WriteString(strconv.Itoa(reset.Value))
+	buffer.WriteByte(';')
 }
 
 // WriteRenameTable query to buffer.
@@ -127,7 +350,12 @@ func (t Table) WriteRenameTable(buffer *Buffer, table dbm.Table) {
 }
 
 // WriteDropTable query to buffer.
+// Uses DropTableWriter when defined, otherwise writes DROP TABLE statement.
 func (t Table) WriteDropTable(buffer *Buffer, table dbm.Table) {
+	if t.DropTableWriter != nil && t.DropTableWriter(t, buffer, table) {
+		return
+	}
+
 	buffer.WriteString("DROP TABLE ")
 
 	if table.Optional {
@@ -138,32 +366,53 @@ func (t Table) WriteDropTable(buffer *Buffer, table dbm.Table) {
 	buffer.WriteByte(';')
 }
 
-// WriteColumn definition to buffer.
-func (t Table) WriteColumn(buffer *Buffer, column dbm.Column) {
+// MapColumnType returns the database type of column including its size,
+// the column may be modified by ColumnMapper to match the database.
+func (t Table) MapColumnType(column *dbm.Column) string {
 	var (
-		typ, m, n = t.ColumnMapper(&column)
+		typ, m, n = t.ColumnMapper(column)
 	)
 
-	buffer.WriteEscape(column.Name)
-	buffer.WriteByte(' ')
-	buffer.WriteString(typ)
-
 	if m != 0 {
-		buffer.WriteByte('(')
-		buffer.WriteString(strconv.Itoa(m))
+		typ += "(" + strconv.Itoa(m)
 
 		if n != 0 {
-			buffer.WriteByte(',')
-			buffer.WriteString(strconv.Itoa(n))
+			typ += "," + strconv.Itoa(n)
 		}
 
-		buffer.WriteByte(')')
+		typ += ")"
 	}
 
+	return typ
+}
+
+// WriteColumn definition to buffer.
+// Values that remain after ColumnMapper are enforced using a CHECK constraint.
+func (t Table) WriteColumn(buffer *Buffer, column dbm.Column) {
+	var (
+		typ = t.MapColumnType(&column)
+	)
+
+	buffer.WriteEscape(column.Name)
+	buffer.WriteByte(' ')
+	buffer.WriteString(typ)
+
 	if column.Unsigned {
 		buffer.WriteString(" UNSIGNED")
 	}
 
+	t.WriteCollation(buffer, column.Charset, column.Collation)
+
+	if column.Generated != "" {
+		buffer.WriteString(" GENERATED ALWAYS AS (")
+		buffer.WriteString(column.Generated)
+		if column.Stored {
+			buffer.WriteString(") STORED")
+		} else {
+			buffer.WriteString(") VIRTUAL")
+		}
+	}
+
 	if column.Unique {
 		buffer.WriteString(" UNIQUE")
 	}
@@ -178,26 +427,76 @@ func (t Table) WriteColumn(buffer *Buffer, column dbm.Column) {
 
 	if column.Default != nil {
 		buffer.WriteString(" DEFAULT ")
-		buffer.WriteValue(column.Default)
+		t.WriteDefault(buffer, column.Default)
+	}
+
+	if len(column.Values) > 0 {
+		buffer.WriteByte(' ')
+		t.WriteValuesCheck(buffer, column)
+	}
+
+	if t.CommentWriter == nil && column.Comment != "" {
+		buffer.WriteString(" COMMENT ")
+		buffer.WriteString(buffer.Quoter.Value(column.Comment))
 	}
 
 	t.WriteOptions(buffer, column.Options)
 }
 
+// WriteValuesCheck constraint that restricts column to its values to buffer.
+func (t Table) WriteValuesCheck(buffer *Buffer, column dbm.Column) {
+	buffer.WriteString("CHECK (")
+	buffer.WriteEscape(column.Name)
+	buffer.WriteString(" IN (")
+	for i, value := range column.Values {
+		if i > 0 {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteValue(value)
+	}
+	buffer.WriteString("))")
+}
+
+// WriteCollation of table or column to buffer, names are written as is.
+func (t Table) WriteCollation(buffer *Buffer, charset string, collation string) {
+	if charset != "" {
+		buffer.WriteString(" CHARACTER SET ")
+		buffer.WriteString(charset)
+	}
+
+	if collation != "" {
+		buffer.WriteString(" COLLATE ")
+		buffer.WriteString(collation)
+	}
+}
+
+// WriteDefault value of column to buffer, sql expressions are written as is.
+func (t Table) WriteDefault(buffer *Buffer, def any) {
+	if expr, ok := def.(dbm.Expr); ok {
+		buffer.WriteString(string(expr))
+		return
+	}
+
+	buffer.WriteValue(def)
+}
+
 // WriteKey definition to buffer.
 func (t Table) WriteKey(buffer *Buffer, key dbm.Key) {
 	var (
 		typ = string(key.Type)
 	)
 
-	buffer.WriteString(typ)
-
 	if key.Name != "" {
-		buffer.WriteByte(' ')
+		buffer.WriteString("CONSTRAINT ")
 		buffer.WriteEscape(key.Name)
+		buffer.WriteByte(' ')
 	}
 
+	buffer.WriteString(typ)
 	buffer.WriteString(" (")
+	if key.Type == dbm.CheckKey {
+		buffer.WriteString(key.Expression)
+	}
 	for i, col := range key.Columns {
 		if i > 0 {
 			buffer.WriteString(", ")
@@ -219,15 +518,27 @@ func (t Table) WriteKey(buffer *Buffer, key dbm.Key) {
 		}
 		buffer.WriteString(")")
 
-		if onDelete := key.Reference.OnDelete; onDelete != "" {
+		if key.Reference.MatchFull {
+			buffer.WriteString(" MATCH FULL")
+		}
+
+		if onDelete := sql.ReferentialAction(key.Reference.OnDelete); onDelete != "" {
 			buffer.WriteString(" ON DELETE ")
 			buffer.WriteString(onDelete)
 		}
 
-		if onUpdate := key.Reference.OnUpdate; onUpdate != "" {
+		if onUpdate := sql.ReferentialAction(key.Reference.OnUpdate); onUpdate != "" {
 			buffer.WriteString(" ON UPDATE ")
 			buffer.WriteString(onUpdate)
 		}
+
+		if key.Reference.Deferrable || key.Reference.InitiallyDeferred {
+			buffer.WriteString(" DEFERRABLE")
+		}
+
+		if key.Reference.InitiallyDeferred {
+			buffer.WriteString(" INITIALLY DEFERRED")
+		}
 	}
 
 	t.WriteOptions(buffer, key.Options)
@@ -44,7 +44,7 @@ func TestTable_Build(t *testing.T) {
 			},
 		},
 		{
-			result: "CREATE TABLE `columns` (`bool` BOOL NOT NULL DEFAULT false, `int` INT(11) UNSIGNED, `bigint` BIGINT(20) UNSIGNED, `float` FLOAT(24) UNSIGNED, `decimal` DECIMAL(6,2) UNSIGNED, `string` VARCHAR(144) UNIQUE, `text` TEXT(1000), `date` DATE, `datetime` DATETIME DEFAULT '2020-01-01 01:00:00', `time` TIME, `blob` blob, PRIMARY KEY (`int`), FOREIGN KEY (`int`, `string`) REFERENCES `products` (`id`, `name`) ON DELETE CASCADE ON UPDATE CASCADE, UNIQUE `date_unique` (`date`)) Engine=InnoDB;",
+			result: "CREATE TABLE `columns` (`bool` BOOL NOT NULL DEFAULT false, `int` INT UNSIGNED, `bigint` BIGINT UNSIGNED, `float` FLOAT(24) UNSIGNED, `decimal` DECIMAL(6,2) UNSIGNED, `string` VARCHAR(144) UNIQUE, `text` TEXT, `date` DATE, `datetime` DATETIME DEFAULT '2020-01-01 01:00:00', `time` TIME, `blob` blob, PRIMARY KEY (`int`), FOREIGN KEY (`int`, `string`) REFERENCES `products` (`id`, `name`) ON DELETE CASCADE ON UPDATE CASCADE, FOREIGN KEY (`int`, `string`) REFERENCES `variants` (`id`, `name`) MATCH FULL DEFERRABLE, CONSTRAINT `date_unique` UNIQUE (`date`)) Engine=InnoDB;",
 			table: dbm.Table{
 				Op:   dbm.SchemaCreate,
 				Name: "columns",
@@ -62,6 +62,7 @@ func TestTable_Build(t *testing.T) {
 					dbm.Column{Name: "blob", Type: "blob"},
 					dbm.Key{Columns: []string{"int"}, Type: dbm.PrimaryKey},
 					dbm.Key{Columns: []string{"int", "string"}, Type: dbm.ForeignKey, Reference: dbm.ForeignKeyReference{Table: "products", Columns: []string{"id", "name"}, OnDelete: "CASCADE", OnUpdate: "CASCADE"}},
+					dbm.Key{Columns: []string{"int", "string"}, Type: dbm.ForeignKey, Reference: dbm.ForeignKeyReference{Table: "variants", Columns: []string{"id", "name"}, MatchFull: true, Deferrable: true}},
 					dbm.Key{Columns: []string{"date"}, Name: "date_unique", Type: dbm.UniqueKey},
 				},
 				Options: "Engine=InnoDB",
@@ -81,7 +82,7 @@ func TestTable_Build(t *testing.T) {
 			},
 		},
 		{
-			result: "ALTER TABLE `columns` ADD COLUMN `verified` BOOL;ALTER TABLE `columns` RENAME COLUMN `string` TO `name`;ALTER TABLE `columns` ;ALTER TABLE `columns` DROP COLUMN `blob`;",
+			result: "ALTER TABLE `columns` ADD COLUMN `verified` BOOL;ALTER TABLE `columns` RENAME COLUMN `string` TO `name`;ALTER TABLE `columns` ALTER COLUMN `bool` TYPE INT USING `bool`::INT, ALTER COLUMN `bool` DROP NOT NULL, ALTER COLUMN `bool` DROP DEFAULT;ALTER TABLE `columns` DROP COLUMN `blob`;",
 			table: dbm.Table{
 				Op:   dbm.SchemaAlter,
 				Name: "columns",
@@ -93,6 +94,20 @@ func TestTable_Build(t *testing.T) {
 				},
 			},
 		},
+		{
+			result: "ALTER TABLE `columns` ALTER COLUMN `string` TYPE VARCHAR(512) USING trim(`string`), ALTER COLUMN `string` SET NOT NULL, ALTER COLUMN `string` SET DEFAULT '';ALTER TABLE `columns` ALTER COLUMN `int` SET NOT NULL;ALTER TABLE `columns` ALTER COLUMN `int` DROP NOT NULL;ALTER TABLE `columns` ALTER COLUMN `int` SET DEFAULT 1;ALTER TABLE `columns` ALTER COLUMN `int` DROP DEFAULT;",
+			table: dbm.Table{
+				Op:   dbm.SchemaAlter,
+				Name: "columns",
+				Definitions: []dbm.TableDefinition{
+					dbm.Column{Name: "string", Type: dbm.String, Limit: 512, Required: true, Default: "", Using: "trim(`string`)", Op: dbm.SchemaAlter},
+					dbm.Column{Name: "int", Type: dbm.Int, Required: true, Op: dbm.SchemaAlter, Change: dbm.ChangeRequired},
+					dbm.Column{Name: "int", Type: dbm.Int, Op: dbm.SchemaAlter, Change: dbm.ChangeRequired},
+					dbm.Column{Name: "int", Default: 1, Op: dbm.SchemaAlter, Change: dbm.ChangeDefault},
+					dbm.Column{Name: "int", Op: dbm.SchemaAlter, Change: dbm.ChangeDefault},
+				},
+			},
+		},
 		{
 			result: "ALTER TABLE `transactions` ADD FOREIGN KEY (`user_id`) REFERENCES `products` (`id`, `name`) ON DELETE CASCADE ON UPDATE CASCADE;",
 			table: dbm.Table{
@@ -103,6 +118,16 @@ func TestTable_Build(t *testing.T) {
 				},
 			},
 		},
+		{
+			result: "ALTER TABLE `transactions` ADD FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE SET NULL ON UPDATE NO ACTION;",
+			table: dbm.Table{
+				Op:   dbm.SchemaAlter,
+				Name: "transactions",
+				Definitions: []dbm.TableDefinition{
+					dbm.Key{Columns: []string{"user_id"}, Type: dbm.ForeignKey, Reference: dbm.ForeignKeyReference{Table: "users", Columns: []string{"id"}, OnDelete: "set  null", OnUpdate: " no action"}},
+				},
+			},
+		},
 		{
 			result: "ALTER TABLE `transactions` DROP CONSTRAINT `fk`;",
 			table: dbm.Table{
@@ -113,6 +138,17 @@ func TestTable_Build(t *testing.T) {
 				},
 			},
 		},
+		{
+			result: "ALTER TABLE `transactions` DROP CONSTRAINT `pk`;ALTER TABLE `transactions` RENAME CONSTRAINT `uq` TO `uq_code`;",
+			table: dbm.Table{
+				Op:   dbm.SchemaAlter,
+				Name: "transactions",
+				Definitions: []dbm.TableDefinition{
+					dbm.Key{Op: dbm.SchemaDrop, Name: "pk", Type: dbm.PrimaryKey},
+					dbm.Key{Op: dbm.SchemaRename, Name: "uq", Rename: "uq_code"},
+				},
+			},
+		},
 		{
 			result: "ALTER TABLE `table` RENAME TO `table1`;",
 			table: dbm.Table{
@@ -169,7 +205,7 @@ func TestTable_BuildWithDefinitionFilter(t *testing.T) {
 		table  dbm.Table
 	}{
 		{
-			result: "CREATE TABLE `columns` (`bool` BOOL NOT NULL DEFAULT false, `int` INT(11) UNSIGNED, `bigint` BIGINT(20) UNSIGNED, `float` FLOAT(24) UNSIGNED, `decimal` DECIMAL(6,2) UNSIGNED, `string` VARCHAR(144) UNIQUE, `text` TEXT(1000), `date` DATE, `datetime` DATETIME DEFAULT '2020-01-01 01:00:00', `time` TIME, `blob` blob, PRIMARY KEY (`int`), FOREIGN KEY (`int`, `string`) REFERENCES `products` (`id`, `name`) ON DELETE CASCADE ON UPDATE CASCADE, UNIQUE `date_unique` (`date`)) Engine=InnoDB;",
+			result: "CREATE TABLE `columns` (`bool` BOOL NOT NULL DEFAULT false, `int` INT UNSIGNED, `bigint` BIGINT UNSIGNED, `float` FLOAT(24) UNSIGNED, `decimal` DECIMAL(6,2) UNSIGNED, `string` VARCHAR(144) UNIQUE, `text` TEXT, `date` DATE, `datetime` DATETIME DEFAULT '2020-01-01 01:00:00', `time` TIME, `blob` blob, PRIMARY KEY (`int`), FOREIGN KEY (`int`, `string`) REFERENCES `products` (`id`, `name`) ON DELETE CASCADE ON UPDATE CASCADE, CONSTRAINT `date_unique` UNIQUE (`date`)) Engine=InnoDB;",
 			table: dbm.Table{
 				Op:   dbm.SchemaCreate,
 				Name: "columns",
@@ -193,7 +229,7 @@ func TestTable_BuildWithDefinitionFilter(t *testing.T) {
 			},
 		},
 		{
-			result: "ALTER TABLE `columns` ADD COLUMN `verified` BOOL;ALTER TABLE `columns` RENAME COLUMN `string` TO `name`;ALTER TABLE `columns` ;ALTER TABLE `columns` DROP COLUMN `blob`;",
+			result: "ALTER TABLE `columns` ADD COLUMN `verified` BOOL;ALTER TABLE `columns` RENAME COLUMN `string` TO `name`;ALTER TABLE `columns` ALTER COLUMN `bool` TYPE INT USING `bool`::INT, ALTER COLUMN `bool` DROP NOT NULL, ALTER COLUMN `bool` DROP DEFAULT;ALTER TABLE `columns` DROP COLUMN `blob`;",
 			table: dbm.Table{
 				Op:   dbm.SchemaAlter,
 				Name: "columns",
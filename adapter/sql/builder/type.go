@@ -0,0 +1,83 @@
+package builder
+
+import (
+	"github.com/jiyeyuran/dbm"
+)
+
+// Type builder of PostgreSQL compatible user defined types.
+type Type struct {
+	BufferFactory BufferFactory
+}
+
+// Build sql query for type.
+func (t Type) Build(typ dbm.Type) string {
+	buffer := t.BufferFactory.Create()
+
+	switch typ.Op {
+	case dbm.SchemaCreate:
+		t.WriteCreateType(&buffer, typ)
+	case dbm.SchemaAlter:
+		t.WriteAlterType(&buffer, typ)
+	case dbm.SchemaDrop:
+		t.WriteDropType(&buffer, typ)
+	}
+
+	return buffer.String()
+}
+
+// WriteCreateType of enum values to buffer, an existing type is kept when optional.
+// The comment is only set when the type is created.
+func (t Type) WriteCreateType(buffer *Buffer, typ dbm.Type) {
+	if typ.Optional {
+		buffer.WriteString("DO $$BEGIN ")
+	}
+
+	buffer.WriteString("CREATE TYPE ")
+	buffer.WriteEscape(typ.Name)
+	buffer.WriteString(" AS ENUM (")
+	for i, value := range typ.Values {
+		if i > 0 {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteValue(value)
+	}
+	buffer.WriteString(");")
+
+	if typ.Comment != "" {
+		buffer.WriteString("COMMENT ON TYPE ")
+		buffer.WriteEscape(typ.Name)
+		buffer.WriteString(" IS ")
+		buffer.WriteValue(typ.Comment)
+		buffer.WriteByte(';')
+	}
+
+	if typ.Optional {
+		buffer.WriteString(" EXCEPTION WHEN duplicate_object THEN NULL; END$$;")
+	}
+}
+
+// WriteAlterType that adds enum values to buffer, existing values are skipped when optional.
+func (t Type) WriteAlterType(buffer *Buffer, typ dbm.Type) {
+	for _, value := range typ.Values {
+		buffer.WriteString("ALTER TYPE ")
+		buffer.WriteEscape(typ.Name)
+		buffer.WriteString(" ADD VALUE ")
+		if typ.Optional {
+			buffer.WriteString("IF NOT EXISTS ")
+		}
+		buffer.WriteValue(value)
+		buffer.WriteByte(';')
+	}
+}
+
+// WriteDropType to buffer.
+func (t Type) WriteDropType(buffer *Buffer, typ dbm.Type) {
+	buffer.WriteString("DROP TYPE ")
+
+	if typ.Optional {
+		buffer.WriteString("IF EXISTS ")
+	}
+
+	buffer.WriteEscape(typ.Name)
+	buffer.WriteByte(';')
+}
@@ -1,16 +1,31 @@
 package sql
 
 import (
+	"context"
+
 	"github.com/jiyeyuran/dbm"
 )
 
 // ErrorMapper function.
 type ErrorMapper func(error) error
 
+// Executor function, runs migrations that can't be built upfront and returns false for the rest.
+type Executor func(ctx context.Context, db dbm.Database, migration interface{}) (bool, error)
+
+// TransactionFilter function, returns false for migrations that can't run in a transaction.
+type TransactionFilter func(migration interface{}) bool
+
+// Validator function, returns dbm.ValidationError for migrations that can't be built into valid queries.
+type Validator func(migration interface{}) error
+
 type SQL struct {
-	TableBuilder TableBuilder
-	IndexBuilder IndexBuilder
-	ErrorMapper  ErrorMapper
+	TableBuilder      TableBuilder
+	IndexBuilder      IndexBuilder
+	TypeBuilder       TypeBuilder
+	ErrorMapper       ErrorMapper
+	Executor          Executor
+	Validator         Validator
+	TransactionFilter TransactionFilter
 }
 
 func (s SQL) Build(migration interface{}) string {
@@ -19,6 +34,10 @@ func (s SQL) Build(migration interface{}) string {
 		return s.TableBuilder.Build(v)
 	case dbm.Index:
 		return s.IndexBuilder.Build(v)
+	case dbm.Type:
+		if s.TypeBuilder != nil {
+			return s.TypeBuilder.Build(v)
+		}
 	case dbm.Raw:
 		return string(v)
 	}
@@ -26,6 +45,30 @@ func (s SQL) Build(migration interface{}) string {
 	return ""
 }
 
+func (s SQL) Exec(ctx context.Context, db dbm.Database, migration interface{}) (bool, error) {
+	if s.Executor == nil {
+		return false, nil
+	}
+	return s.Executor(ctx, db, migration)
+}
+
+// Transactional returns false when migration can't run in a transaction according to TransactionFilter.
+func (s SQL) Transactional(migration interface{}) bool {
+	return s.TransactionFilter == nil || s.TransactionFilter(migration)
+}
+
+// Validate migration using Validator, types are rejected when there's no TypeBuilder.
+func (s SQL) Validate(migration interface{}) error {
+	if typ, ok := migration.(dbm.Type); ok && s.TypeBuilder == nil {
+		return dbm.ValidationError{Table: typ.Name, Message: "types are not supported by the database"}
+	}
+
+	if s.Validator == nil {
+		return nil
+	}
+	return s.Validator(migration)
+}
+
 func (s SQL) WrapError(err error) error {
 	if s.ErrorMapper == nil || err == nil {
 		return err
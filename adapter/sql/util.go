@@ -1,6 +1,12 @@
 package sql
 
 import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,9 +30,8 @@ func DropKeyMapper(keyType dbm.KeyType) string {
 // ColumnMapper function.
 func ColumnMapper(column *dbm.Column) (string, int, int) {
 	var (
-		typ        string
-		m, n       int
-		timeLayout = DefaultTimeLayout
+		typ  string
+		m, n int
 	)
 
 	switch column.Type {
@@ -36,12 +41,11 @@ func ColumnMapper(column *dbm.Column) (string, int, int) {
 		typ = "BIGINT UNSIGNED AUTO_INCREMENT"
 	case dbm.Bool:
 		typ = "BOOL"
-	case dbm.Int:
-		typ = "INT"
-		m = column.Limit
-	case dbm.BigInt:
-		typ = "BIGINT"
-		m = column.Limit
+	case dbm.SmallInt, dbm.Int, dbm.BigInt:
+		typ = IntegerType(*column)
+		if column.Identity != nil {
+			typ += " AUTO_INCREMENT"
+		}
 	case dbm.Float:
 		typ = "FLOAT"
 		m = column.Precision
@@ -49,36 +53,398 @@ func ColumnMapper(column *dbm.Column) (string, int, int) {
 		typ = "DECIMAL"
 		m = column.Precision
 		n = column.Scale
-	case dbm.String:
+	case dbm.String, dbm.Enum:
 		typ = "VARCHAR"
 		m = column.Limit
 		if m == 0 {
 			m = 255
 		}
-	case dbm.Text:
-		typ = "TEXT"
+	case dbm.Char:
+		typ = "CHAR"
 		m = column.Limit
+	case dbm.Text:
+		typ = TextType(*column)
 	case dbm.JSON:
 		typ = "TEXT"
+	case dbm.Binary:
+		typ = "VARBINARY"
+		m = column.Limit
+		if m == 0 {
+			m = 255
+		}
+	case dbm.Blob:
+		typ = "BLOB"
 	case dbm.Date:
 		typ = "DATE"
-		timeLayout = "2006-01-02"
 	case dbm.DateTime:
 		typ = "DATETIME"
 	case dbm.Time:
 		typ = "TIME"
-		timeLayout = "15:04:05"
 	default:
 		typ = string(column.Type)
 	}
 
-	if t, ok := column.Default.(time.Time); ok {
-		column.Default = t.Format(timeLayout)
-	}
+	NormalizeDefault(column)
 
 	return typ, m, n
 }
 
+// IntegerSize of column in bytes, defined by Limit or by the number of digits in Precision.
+// Defaults to the size of its type when neither is defined, limits above 8 bytes are display widths and are ignored.
+func IntegerSize(column dbm.Column) int {
+	switch {
+	case column.Limit > 0 && column.Limit <= 8:
+		return column.Limit
+	case column.Precision > 0 && column.Precision <= 2:
+		return 1
+	case column.Precision > 0 && column.Precision <= 4:
+		return 2
+	case column.Precision > 0 && column.Precision <= 6:
+		return 3
+	case column.Precision > 0 && column.Precision <= 9:
+		return 4
+	case column.Precision > 0:
+		return 8
+	}
+
+	switch column.Type {
+	case dbm.SmallInt:
+		return 2
+	case dbm.BigInt:
+		return 8
+	default:
+		return 4
+	}
+}
+
+// IntegerType that stores the IntegerSize of column.
+func IntegerType(column dbm.Column) string {
+	switch size := IntegerSize(column); {
+	case size <= 1:
+		return "TINYINT"
+	case size <= 2:
+		return "SMALLINT"
+	case size <= 3:
+		return "MEDIUMINT"
+	case size <= 4:
+		return "INT"
+	default:
+		return "BIGINT"
+	}
+}
+
+// TextType that stores Limit bytes of text column.
+func TextType(column dbm.Column) string {
+	switch {
+	case column.Limit > 16777215:
+		return "LONGTEXT"
+	case column.Limit > 65535:
+		return "MEDIUMTEXT"
+	default:
+		return "TEXT"
+	}
+}
+
+// TimeLayout of column type, with fractional seconds up to the given precision.
+func TimeLayout(typ dbm.ColumnType, precision int) string {
+	var layout = DefaultTimeLayout
+
+	switch typ {
+	case dbm.Date:
+		return "2006-01-02"
+	case dbm.Time:
+		layout = "15:04:05"
+	}
+
+	if precision > 9 {
+		precision = 9
+	}
+
+	if precision > 0 {
+		layout += "." + strings.Repeat("0", precision)
+	}
+
+	return layout
+}
+
+// TimeDefault formats time default of column using TimeLayout with the given precision.
+// The offset is appended to DateTime defaults when withOffset is true.
+func TimeDefault(column *dbm.Column, precision int, withOffset bool) {
+	t, ok := column.Default.(time.Time)
+	if !ok {
+		return
+	}
+
+	layout := TimeLayout(column.Type, precision)
+	if withOffset && column.Type == dbm.DateTime {
+		layout += "-07:00"
+	}
+
+	column.Default = t.Format(layout)
+}
+
+// NormalizeDefault converts the default of a column to the value expected by its type.
+// Integers of bool columns are converted to bool, JSON values are marshaled, time is formatted using TimeLayout
+// and bytes of binary columns are written as X'..' hex literal.
+// Defaults that can't be converted are kept as is and rejected by Validate.
+func NormalizeDefault(column *dbm.Column) {
+	switch column.Default.(type) {
+	case nil, dbm.Expr:
+		return
+	case time.Time:
+		TimeDefault(column, column.Precision, false)
+		return
+	}
+
+	switch column.Type {
+	case dbm.Bool:
+		if i, ok := intValue(column.Default); ok && (i == 0 || i == 1) {
+			column.Default = i == 1
+		}
+	case dbm.Binary, dbm.Blob:
+		HexDefault(column, "X'", "'")
+	case dbm.JSON:
+		switch column.Default.(type) {
+		case string, []byte:
+			// already encoded.
+		default:
+			if b, err := json.Marshal(column.Default); err == nil {
+				column.Default = string(b)
+			}
+		}
+	}
+}
+
+// HexDefault converts bytes default of binary columns to a hex literal surrounded by prefix and suffix.
+func HexDefault(column *dbm.Column, prefix string, suffix string) {
+	if b, ok := column.Default.([]byte); ok && (column.Type == dbm.Binary || column.Type == dbm.Blob) {
+		column.Default = dbm.Expr(prefix + hex.EncodeToString(b) + suffix)
+	}
+}
+
+// Validate migration, it rejects key and column definitions that can't be built, such as defaults that are not valid for the type of their column.
+func Validate(migration interface{}) error {
+	if err := ValidateKeys(migration, ValidateKey); err != nil {
+		return err
+	}
+
+	return ValidateColumns(migration, ValidateColumn)
+}
+
+// ValidateTable migration using fn, errors are returned as dbm.ValidationError of the table.
+func ValidateTable(migration interface{}, fn func(table dbm.Table) error) error {
+	table, ok := migration.(dbm.Table)
+	if !ok {
+		return nil
+	}
+
+	if err := fn(table); err != nil {
+		return dbm.ValidationError{Table: table.Name, Message: err.Error()}
+	}
+
+	return nil
+}
+
+// ValidateIndex migration using fn, errors are returned as dbm.ValidationError of the index.
+func ValidateIndex(migration interface{}, fn func(index dbm.Index) error) error {
+	index, ok := migration.(dbm.Index)
+	if !ok {
+		return nil
+	}
+
+	if err := fn(index); err != nil {
+		return dbm.ValidationError{Table: index.Table, Name: index.Name, Message: err.Error()}
+	}
+
+	return nil
+}
+
+// ValidateColumns of table migration using fn, errors are returned as dbm.ValidationError of the column.
+func ValidateColumns(migration interface{}, fn func(table dbm.Table, column dbm.Column) error) error {
+	table, ok := migration.(dbm.Table)
+	if !ok {
+		return nil
+	}
+
+	for _, def := range table.Definitions {
+		column, ok := def.(dbm.Column)
+		if !ok {
+			continue
+		}
+
+		if err := fn(table, column); err != nil {
+			return dbm.ValidationError{Table: table.Name, Name: column.Name, Message: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// ValidateKeys of table migration using fn, errors are returned as dbm.ValidationError of the key.
+func ValidateKeys(migration interface{}, fn func(table dbm.Table, key dbm.Key) error) error {
+	table, ok := migration.(dbm.Table)
+	if !ok {
+		return nil
+	}
+
+	for _, def := range table.Definitions {
+		key, ok := def.(dbm.Key)
+		if !ok {
+			continue
+		}
+
+		if err := fn(table, key); err != nil {
+			return dbm.ValidationError{Table: table.Name, Name: key.Name, Message: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// ValidateKey returns an error when the key definition is not valid regardless of database.
+func ValidateKey(table dbm.Table, key dbm.Key) error {
+	if key.Op == dbm.SchemaRename && key.Type == "" {
+		return errors.New("key type is required to rename a key")
+	}
+
+	if key.Op != dbm.SchemaCreate || key.Type != dbm.ForeignKey {
+		return nil
+	}
+
+	ref := key.Reference
+	if len(key.Columns) == 0 || len(key.Columns) != len(ref.Columns) {
+		return fmt.Errorf("foreign key has %d columns but references %d columns", len(key.Columns), len(ref.Columns))
+	}
+
+	for _, action := range []string{ref.OnDelete, ref.OnUpdate} {
+		switch ReferentialAction(action) {
+		case "", "CASCADE", "SET NULL", "SET DEFAULT", "RESTRICT", "NO ACTION":
+		default:
+			return fmt.Errorf("%q is not a valid referential action, it must be one of CASCADE, SET NULL, SET DEFAULT, RESTRICT or NO ACTION", action)
+		}
+	}
+
+	return nil
+}
+
+// ReferentialAction normalizes OnDelete and OnUpdate action of foreign key to upper case words separated by a single space.
+func ReferentialAction(action string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(action)), " ")
+}
+
+// ValidateColumn returns an error when the column definition is not valid regardless of database.
+func ValidateColumn(table dbm.Table, column dbm.Column) error {
+	if column.Op == dbm.SchemaAlter {
+		switch column.Type {
+		case "":
+			return errors.New("column type is required to alter a column")
+		case dbm.ID, dbm.BigID, dbm.UUIDID:
+			// these types define a generated primary key, which can't be restated when altering a column.
+			return fmt.Errorf("%s type can't be used to alter a column, use the type of its values such as Int, BigInt or UUID", column.Type)
+		}
+	}
+
+	if isInteger(column.Type) && column.Limit < 0 {
+		return fmt.Errorf("limit %d is not a valid integer size", column.Limit)
+	}
+
+	if column.AutoUpdate && column.Type != dbm.DateTime {
+		return errors.New("auto update requires a datetime column")
+	}
+
+	if column.Type == dbm.Enum && len(column.Values) == 0 {
+		return errors.New("enum requires values")
+	}
+
+	if column.Generated != "" && column.Default != nil {
+		return errors.New("generated column can't have a default")
+	}
+
+	if column.Charset != "" || column.Collation != "" {
+		switch column.Type {
+		case dbm.String, dbm.Char, dbm.Text, dbm.Enum:
+		default:
+			return errors.New("charset and collation require a string column")
+		}
+	}
+
+	if column.Identity != nil {
+		switch {
+		case column.Type != dbm.ID && column.Type != dbm.BigID && !isInteger(column.Type):
+			return errors.New("identity requires an integer column")
+		case column.Default != nil || column.Generated != "":
+			return errors.New("identity column can't have a default or be generated")
+		}
+	}
+
+	return ValidateDefault(column)
+}
+
+// ValidateDefault returns an error when the default can't be used for the type of column.
+func ValidateDefault(column dbm.Column) error {
+	var (
+		def   = column.Default
+		valid = true
+	)
+
+	switch def.(type) {
+	case nil, dbm.Expr:
+		return nil
+	}
+
+	switch column.Type {
+	case dbm.Bool:
+		i, isInt := intValue(def)
+		_, isBool := def.(bool)
+		valid = isBool || isInt && (i == 0 || i == 1)
+	case dbm.ID, dbm.BigID, dbm.SmallInt, dbm.Int, dbm.BigInt:
+		if s, ok := def.(string); ok {
+			_, err := strconv.ParseInt(s, 10, 64)
+			valid = err == nil
+		} else {
+			_, valid = intValue(def)
+		}
+	case dbm.Float, dbm.Decimal:
+		if s, ok := def.(string); ok {
+			_, err := strconv.ParseFloat(s, 64)
+			valid = err == nil
+		} else {
+			_, isInt := intValue(def)
+			kind := reflect.TypeOf(def).Kind()
+			valid = isInt || kind == reflect.Float32 || kind == reflect.Float64
+		}
+	case dbm.String, dbm.Char, dbm.Text, dbm.UUID, dbm.UUIDID, dbm.Binary, dbm.Blob:
+		switch def.(type) {
+		case string, []byte:
+		default:
+			valid = false
+		}
+	case dbm.Enum:
+		valid = false
+		for _, value := range column.Values {
+			if def == value {
+				valid = true
+				break
+			}
+		}
+	case dbm.Date, dbm.DateTime, dbm.Time:
+		switch def.(type) {
+		case string, time.Time:
+		default:
+			valid = false
+		}
+	case dbm.JSON:
+		_, err := json.Marshal(def)
+		valid = err == nil
+	}
+
+	if !valid {
+		return fmt.Errorf("default %#v is not a valid %s", def, strings.ToLower(string(column.Type)))
+	}
+
+	return nil
+}
+
 // ExtractString between two string.
 func ExtractString(s, left, right string) string {
 	var (
@@ -93,6 +459,22 @@ func ExtractString(s, left, right string) string {
 	return s[start+len(left) : end]
 }
 
+func isInteger(typ dbm.ColumnType) bool {
+	return typ == dbm.SmallInt || typ == dbm.Int || typ == dbm.BigInt
+}
+
+// intValue of any integer kind.
+func intValue(i any) (int64, bool) {
+	rv := reflect.ValueOf(i)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true
+	}
+	return 0, false
+}
+
 func toInt64(i any) int64 {
 	var result int64
 
@@ -1,14 +1,26 @@
 package adapter
 
 import (
+	"errors"
 	"log"
+	"strconv"
 	"strings"
 
 	"github.com/jiyeyuran/dbm"
 	"github.com/jiyeyuran/dbm/adapter/sql"
+	"github.com/jiyeyuran/dbm/adapter/sql/builder"
 )
 
-type sqlite3 struct{}
+// SQLite3Options configures the SQLite3 adapter.
+type SQLite3Options struct {
+	// Unsigned chooses how unsigned integer columns are emulated, UNSIGNED is only written as part of the type name by default.
+	Unsigned UnsignedMode
+}
+
+type sqlite3 struct {
+	builder.Quote
+	options SQLite3Options
+}
 
 func (sqlite3) errorMapper(err error) error {
 	if err == nil {
@@ -44,23 +56,53 @@ func (sqlite3) errorMapper(err error) error {
 	}
 }
 
-func (sqlite3) columnMapper(column *dbm.Column) (string, int, int) {
+func (s sqlite3) columnMapper(column *dbm.Column) (string, int, int) {
+	excludeAutoUpdate("SQLite3", column)
+	emulateUnsigned(column, s.options.Unsigned, s.ID)
+
 	var (
 		typ      string
 		m, n     int
 		unsigned = column.Unsigned
+		identity = column.Identity
 	)
 
 	column.Unsigned = false
+	// only ID columns are generated, identity of other columns is rejected by validate.
+	column.Identity = nil
+
+	switch column.Default {
+	case dbm.ExprNow:
+		switch column.Type {
+		case dbm.Date:
+			column.Default = dbm.Expr("CURRENT_DATE")
+		case dbm.Time:
+			column.Default = dbm.Expr("CURRENT_TIME")
+		}
+	case dbm.ExprUUID:
+		// random version 4 UUID, there's no builtin function to generate it.
+		column.Default = dbm.Expr("(lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' ||" +
+			" substr('89ab', abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6))))")
+	}
 
 	switch column.Type {
 	case dbm.ID:
 		typ = "INTEGER"
+		if identity != nil && column.Primary {
+			// the sequence is kept in sqlite_sequence, so it can be reset and values are never reused.
+			column.Options = strings.TrimSpace("AUTOINCREMENT " + column.Options)
+		}
 	case dbm.BigID:
 		typ = "BIGINT"
-	case dbm.Int:
+	case dbm.UUID, dbm.UUIDID:
+		typ = "TEXT"
+	case dbm.Binary, dbm.Blob:
+		typ = "BLOB"
+	case dbm.SmallInt, dbm.Int:
+		// storage size of integers is determined by their value.
 		typ = "INTEGER"
-		m = column.Limit
+	case dbm.Text:
+		typ = "TEXT"
 	default:
 		typ, m, n = sql.ColumnMapper(column)
 	}
@@ -69,16 +111,17 @@ func (sqlite3) columnMapper(column *dbm.Column) (string, int, int) {
 		typ = "UNSIGNED " + typ
 	}
 
+	sql.NormalizeDefault(column)
+
 	return typ, m, n
 }
 
-func (sqlite3) definitionFilter(table dbm.Table, def dbm.TableDefinition) bool {
+func (s sqlite3) definitionFilter(table dbm.Table, def dbm.TableDefinition) bool {
 	if table.Op == dbm.SchemaAlter {
 		// https://www.sqlite.org/omitted.html
 		// > Only the RENAME TABLE, ADD COLUMN, RENAME COLUMN, and DROP COLUMN variants of the ALTER TABLE command are supported.
-		_, ok := def.(dbm.Key)
-		if ok {
-			log.Print("[DBM] SQLite3 adapter does not support adding keys when modifying tables")
+		if s.rebuildDefinition(def) {
+			log.Print("[DBM] SQLite3 adapter requires a table rebuild when changing columns or keys, it's only available when running migrations")
 
 			return false
 		}
@@ -86,3 +129,104 @@ func (sqlite3) definitionFilter(table dbm.Table, def dbm.TableDefinition) bool {
 
 	return true
 }
+
+func (s sqlite3) validate(migration interface{}) error {
+	if err := sql.ValidateIndex(migration, indexFeatures{database: "SQLite", where: true, expressions: true}.validate); err != nil {
+		return err
+	}
+
+	if err := sql.ValidateKeys(migration, foreignKeyFeatures{database: "SQLite", deferrable: true}.validate); err != nil {
+		return err
+	}
+
+	if err := sql.ValidateTable(migration, validateTableCollation); err != nil {
+		return err
+	}
+
+	return sql.ValidateColumns(migration, func(table dbm.Table, column dbm.Column) error {
+		if err := validateCollation(column, false); err != nil {
+			return err
+		}
+
+		if err := validateUnsigned(column, s.options.Unsigned); err != nil {
+			return err
+		}
+
+		if table.Op == dbm.SchemaAlter && column.Op == dbm.SchemaCreate && column.Generated != "" && column.Stored {
+			return errors.New("stored generated columns can't be added to an existing table")
+		}
+
+		if column.Identity != nil && (column.Type != dbm.ID || column.Identity.Start != 0 || column.Identity.Increment != 0) {
+			return errors.New("identity is only supported by ID columns without start and increment")
+		}
+
+		return sql.ValidateColumn(table, column)
+	})
+}
+
+// identityResetWriter replaces the sequence of AUTOINCREMENT column, so the next generated value is reset value.
+// The sequence only exists for ID columns defined with Identity option, other tables are rejected by the executor.
+// There's no row for tables that never had rows, so the row is replaced instead of updated.
+func (s sqlite3) identityResetWriter(buffer *builder.Buffer, table dbm.Table, reset dbm.IdentityReset) {
+	buffer.WriteString("DELETE FROM sqlite_sequence WHERE name = " + s.Value(table.Name) + ";")
+	buffer.WriteString("INSERT INTO sqlite_sequence (name, seq) VALUES (" + s.Value(table.Name) + ", " + strconv.Itoa(reset.Value-1) + ");")
+}
+
+func (sqlite3) commentWriter(buffer *builder.Buffer, comment builder.Comment) {
+	log.Print("[DBM] SQLite3 adapter does not support comments, it has been excluded")
+}
+
+// fullTextIndexWriter creates an FTS5 table using the indexed table as external content,
+// triggers keep it in sync with the indexed table. See https://www.sqlite.org/fts5.html#external_content_tables
+func (s sqlite3) fullTextIndexWriter(i builder.Index, buffer *builder.Buffer, index dbm.Index) bool {
+	var (
+		name     = s.ID(index.Name)
+		triggers = []string{s.ID(index.Name + "_ai"), s.ID(index.Name + "_ad"), s.ID(index.Name + "_au")}
+	)
+
+	if index.Op == dbm.SchemaDrop {
+		var optional string
+		if index.Optional {
+			optional = "IF EXISTS "
+		}
+
+		for _, trigger := range triggers {
+			buffer.WriteString("DROP TRIGGER " + optional + trigger + ";")
+		}
+		buffer.WriteString("DROP TABLE " + optional + name + ";")
+		return true
+	}
+
+	var (
+		optional  string
+		columns   = make([]string, len(index.Columns))
+		newValues = make([]string, len(index.Columns))
+		oldValues = make([]string, len(index.Columns))
+	)
+
+	if index.Optional {
+		optional = "IF NOT EXISTS "
+	}
+
+	for n, col := range index.Columns {
+		columns[n] = s.ID(col)
+		newValues[n] = "new." + columns[n]
+		oldValues[n] = "old." + columns[n]
+	}
+
+	var (
+		insert = "INSERT INTO " + name + " (rowid, " + strings.Join(columns, ", ") + ") VALUES (new.rowid, " + strings.Join(newValues, ", ") + ");"
+		remove = "INSERT INTO " + name + " (" + name + ", rowid, " + strings.Join(columns, ", ") + ") VALUES ('delete', old.rowid, " + strings.Join(oldValues, ", ") + ");"
+	)
+
+	buffer.WriteString("CREATE VIRTUAL TABLE " + optional + name + " USING fts5(" + strings.Join(columns, ", ") + ", content=" + s.Value(index.Table))
+	if index.Options != "" {
+		buffer.WriteString(", " + index.Options)
+	}
+	buffer.WriteString(");")
+	buffer.WriteString("INSERT INTO " + name + " (" + name + ") VALUES ('rebuild');")
+	buffer.WriteString("CREATE TRIGGER " + optional + triggers[0] + " AFTER INSERT ON " + s.ID(index.Table) + " BEGIN " + insert + " END;")
+	buffer.WriteString("CREATE TRIGGER " + optional + triggers[1] + " AFTER DELETE ON " + s.ID(index.Table) + " BEGIN " + remove + " END;")
+	buffer.WriteString("CREATE TRIGGER " + optional + triggers[2] + " AFTER UPDATE ON " + s.ID(index.Table) + " BEGIN " + remove + " " + insert + " END;")
+	return true
+}
@@ -0,0 +1,782 @@
+package adapter
+
+import (
+	"context"
+	dsql "database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jiyeyuran/dbm"
+	"github.com/jiyeyuran/dbm/adapter/sql"
+	"github.com/jiyeyuran/dbm/adapter/sql/builder"
+)
+
+// sqlite3Table is the definition of an existing table, used to rebuild it.
+type sqlite3Table struct {
+	Name string
+	// SQL of the create table statement, unchanged columns and constraints are kept as written.
+	SQL string
+	// Statements of indexes and triggers that are dropped together with the table.
+	Statements []string
+}
+
+// executor runs alter table migrations that SQLite can't do natively as a table rebuild,
+// and index renames by recreating the index. Tables of full-text indexes are checked before the index is created,
+// and tables of identity resets are checked before their sequence is replaced.
+// See https://www.sqlite.org/lang_altertable.html#otheralter
+func (s sqlite3) executor(tableBuilder builder.Table) sql.Executor {
+	return func(ctx context.Context, db dbm.Database, migration interface{}) (bool, error) {
+		if index, ok := migration.(dbm.Index); ok && index.Op == dbm.SchemaRename {
+			return true, s.renameIndex(ctx, db, tableBuilder.BufferFactory, index)
+		}
+
+		if index, ok := migration.(dbm.Index); ok && index.Op == dbm.SchemaCreate && index.FullText {
+			return false, s.requireRowID(ctx, db, tableBuilder.BufferFactory, index)
+		}
+
+		table, ok := migration.(dbm.Table)
+		if ok && table.Op == dbm.SchemaAlter && s.resetsIdentity(table) {
+			if err := s.requireAutoincrement(ctx, db, tableBuilder.BufferFactory, table.Name); err != nil {
+				return false, err
+			}
+		}
+
+		if !ok || table.Op != dbm.SchemaAlter || !s.requiresRebuild(table) {
+			return false, nil
+		}
+
+		// foreign_keys pragma applies to a connection, so the rebuild must not be spread across the pool.
+		if connector, ok := db.(sqlite3Connector); ok {
+			conn, err := connector.Conn(ctx)
+			if err != nil {
+				return true, err
+			}
+			defer conn.Close()
+			db = conn
+		}
+
+		var (
+			native  = table
+			pending []dbm.TableDefinition
+		)
+
+		native.Definitions = nil
+
+		// consecutive definitions are grouped so renames and drops keep their order relative to rebuilds.
+		flush := func() error {
+			if len(native.Definitions) > 0 {
+				if _, err := db.ExecContext(ctx, tableBuilder.Build(native)); err != nil {
+					return err
+				}
+				native.Definitions = nil
+			}
+
+			if len(pending) > 0 {
+				current, err := s.introspect(ctx, db, tableBuilder.BufferFactory, table.Name)
+				if err != nil {
+					return err
+				}
+
+				statements, err := s.rebuild(tableBuilder, current, pending)
+				if err != nil {
+					return err
+				}
+
+				if err := s.execRebuild(ctx, db, table.Name, statements); err != nil {
+					return err
+				}
+				pending = nil
+			}
+
+			return nil
+		}
+
+		for _, def := range table.Definitions {
+			if s.rebuildDefinition(def) {
+				if len(native.Definitions) > 0 {
+					if err := flush(); err != nil {
+						return true, err
+					}
+				}
+				pending = append(pending, def)
+			} else {
+				if len(pending) > 0 {
+					if err := flush(); err != nil {
+						return true, err
+					}
+				}
+				native.Definitions = append(native.Definitions, def)
+			}
+		}
+
+		return true, flush()
+	}
+}
+
+// sqlite3Connector is a database that can reserve a single connection, such as *sql.DB.
+type sqlite3Connector interface {
+	Conn(ctx context.Context) (*dsql.Conn, error)
+}
+
+// execRebuild runs rebuild statements in a transaction as described by https://www.sqlite.org/lang_altertable.html#otheralter.
+// Foreign keys are disabled before the transaction starts, otherwise dropping the original table deletes or updates referencing rows,
+// and they are checked before the rebuild is committed.
+// The pragma has no effect when the migration already runs in a transaction, tables referenced by foreign keys are refused then.
+func (sqlite3) execRebuild(ctx context.Context, db dbm.Database, name string, statements []string) (err error) {
+	checkForeignKeys, err := sqlite3ForeignKeys(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if checkForeignKeys {
+		if _, err := db.ExecContext(ctx, "PRAGMA foreign_keys=OFF;"); err != nil {
+			return err
+		}
+
+		var enabled, referenced bool
+		if enabled, err = sqlite3ForeignKeys(ctx, db); err != nil {
+			return err
+		}
+
+		if !enabled {
+			conn := db
+			defer func() {
+				if _, onErr := conn.ExecContext(ctx, "PRAGMA foreign_keys=ON;"); err == nil {
+					err = onErr
+				}
+			}()
+		} else if referenced, err = sqlite3Referenced(ctx, db, name); err != nil {
+			return err
+		} else if referenced {
+			return fmt.Errorf("dbm: table `%s` is referenced by foreign keys and can't be rebuilt inside a transaction, use Schema.NoTransaction", name)
+		}
+	}
+
+	var tx *dsql.Tx
+	if conn, ok := db.(*dsql.Conn); ok {
+		if tx, err = conn.BeginTx(ctx, nil); err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		db = tx
+	}
+
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	if checkForeignKeys {
+		var violations int
+		if err := queryRows(ctx, db, "PRAGMA foreign_key_check", func(rows *dsql.Rows) error {
+			violations++
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if violations > 0 {
+			return fmt.Errorf("dbm: rebuild of table `%s` violates %d foreign key constraints", name, violations)
+		}
+	}
+
+	if tx != nil {
+		return tx.Commit()
+	}
+	return nil
+}
+
+func sqlite3ForeignKeys(ctx context.Context, db dbm.Database) (bool, error) {
+	var enabled int
+	err := queryRows(ctx, db, "PRAGMA foreign_keys", func(rows *dsql.Rows) error {
+		return rows.Scan(&enabled)
+	})
+	return enabled == 1, err
+}
+
+// sqlite3Referenced returns true when other tables have foreign keys referencing table.
+func sqlite3Referenced(ctx context.Context, db dbm.Database, name string) (bool, error) {
+	var (
+		referenced bool
+		quoted     = "'" + strings.ReplaceAll(name, "'", "''") + "'"
+	)
+
+	err := queryRows(ctx, db, "SELECT m.name FROM sqlite_master m JOIN pragma_foreign_key_list(m.name) f"+
+		" WHERE m.type = 'table' AND m.name != "+quoted+" AND f.\"table\" = "+quoted+" COLLATE NOCASE", func(rows *dsql.Rows) error {
+		referenced = true
+		return nil
+	})
+	return referenced, err
+}
+
+func (s sqlite3) requiresRebuild(table dbm.Table) bool {
+	for _, def := range table.Definitions {
+		if s.rebuildDefinition(def) {
+			return true
+		}
+	}
+	return false
+}
+
+func (sqlite3) rebuildDefinition(def dbm.TableDefinition) bool {
+	switch v := def.(type) {
+	case dbm.Column:
+		return v.Op == dbm.SchemaAlter
+	case dbm.Key:
+		return true
+	}
+	return false
+}
+
+// introspect existing table definition from sqlite_master.
+func (sqlite3) introspect(ctx context.Context, db dbm.Database, bufferFactory builder.BufferFactory, name string) (sqlite3Table, error) {
+	var (
+		table = sqlite3Table{Name: name}
+		quote = bufferFactory.Quoter.Value
+	)
+
+	if err := queryRows(ctx, db, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = "+quote(name), func(rows *dsql.Rows) error {
+		return rows.Scan(&table.SQL)
+	}); err != nil {
+		return table, err
+	}
+
+	if err := queryRows(ctx, db, "SELECT sql FROM sqlite_master WHERE type IN ('index', 'trigger') AND sql IS NOT NULL AND tbl_name = "+quote(name), func(rows *dsql.Rows) error {
+		var statement string
+		if err := rows.Scan(&statement); err != nil {
+			return err
+		}
+
+		table.Statements = append(table.Statements, statement)
+		return nil
+	}); err != nil {
+		return table, err
+	}
+
+	return table, nil
+}
+
+// requireRowID returns an error when the table of full-text index is a WITHOUT ROWID table,
+// as the index looks up its external content by rowid.
+func (sqlite3) requireRowID(ctx context.Context, db dbm.Database, bufferFactory builder.BufferFactory, index dbm.Index) error {
+	var table string
+	if err := queryRows(ctx, db, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = "+bufferFactory.Quoter.Value(index.Table), func(rows *dsql.Rows) error {
+		return rows.Scan(&table)
+	}); err != nil {
+		return err
+	}
+
+	_, suffix, _ := parseSQLite3Table(table)
+	for i := 0; i+1 < len(suffix); i++ {
+		if strings.EqualFold(suffix[i], "WITHOUT") && strings.EqualFold(suffix[i+1], "ROWID") {
+			return fmt.Errorf("dbm: full-text index `%s` requires table `%s` to have a rowid", index.Name, index.Table)
+		}
+	}
+
+	return nil
+}
+
+func (sqlite3) resetsIdentity(table dbm.Table) bool {
+	for _, def := range table.Definitions {
+		if _, ok := def.(dbm.IdentityReset); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// requireAutoincrement returns an error when table has no AUTOINCREMENT column,
+// as only those tables have a sequence in sqlite_sequence, which doesn't exist until such table is created.
+func (sqlite3) requireAutoincrement(ctx context.Context, db dbm.Database, bufferFactory builder.BufferFactory, name string) error {
+	var table string
+	if err := queryRows(ctx, db, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = "+bufferFactory.Quoter.Value(name), func(rows *dsql.Rows) error {
+		return rows.Scan(&table)
+	}); err != nil {
+		return err
+	}
+
+	definitions, _, _ := parseSQLite3Table(table)
+	for _, def := range definitions {
+		for _, token := range def {
+			if strings.EqualFold(token, "AUTOINCREMENT") {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("dbm: identity of table `%s` can't be reset as it has no AUTOINCREMENT column, use ID with Identity option", name)
+}
+
+// renameIndex creates the index under its new name from its introspected definition, then drops the original index.
+func (sqlite3) renameIndex(ctx context.Context, db dbm.Database, bufferFactory builder.BufferFactory, index dbm.Index) error {
+	var (
+		quoter    = bufferFactory.Quoter
+		statement string
+	)
+
+	if err := queryRows(ctx, db, "SELECT sql FROM sqlite_master WHERE type = 'index' AND sql IS NOT NULL AND name = "+quoter.Value(index.Name), func(rows *dsql.Rows) error {
+		return rows.Scan(&statement)
+	}); err != nil {
+		return err
+	}
+
+	if statement == "" {
+		return fmt.Errorf("dbm: index `%s` not found or created by a constraint", index.Name)
+	}
+
+	statement, ok := renameSQLite3Index(statement, quoter.ID(index.Rename))
+	if !ok {
+		return fmt.Errorf("dbm: unable to parse definition of index `%s`", index.Name)
+	}
+
+	for _, statement := range []string{statement, "DROP INDEX " + quoter.ID(index.Name) + ";"} {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renameSQLite3Index replaces the index name of create index statement.
+func renameSQLite3Index(sql string, name string) (string, bool) {
+	tokens := sqlite3Tokens(sql)
+
+	for i := range tokens {
+		if !strings.EqualFold(tokens[i], "INDEX") {
+			continue
+		}
+
+		i++
+		if i+2 < len(tokens) && strings.EqualFold(tokens[i], "IF") {
+			i += 3
+		}
+
+		if i+1 >= len(tokens) || !strings.EqualFold(tokens[i+1], "ON") {
+			return "", false
+		}
+
+		tokens[i] = name
+		return strings.Join(tokens, " ") + ";", true
+	}
+
+	return "", false
+}
+
+// rebuild returns statements that recreate the table with the changed definitions applied and copy existing rows.
+func (s sqlite3) rebuild(tableBuilder builder.Table, current sqlite3Table, changes []dbm.TableDefinition) ([]string, error) {
+	var (
+		temporary  = "dbm_rebuild_" + current.Name
+		buffer     = tableBuilder.BufferFactory.Create()
+		statements []string
+	)
+
+	definitions, suffix, ok := parseSQLite3Table(current.SQL)
+	if !ok {
+		return nil, fmt.Errorf("dbm: unable to parse definition of table `%s`", current.Name)
+	}
+
+	for _, change := range changes {
+		var err error
+
+		switch v := change.(type) {
+		case dbm.Column:
+			err = definitions.alterColumn(tableBuilder, v)
+		case dbm.Key:
+			definitions, err = definitions.alterKey(tableBuilder, v)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("dbm: %w in table `%s`", err, current.Name)
+		}
+	}
+
+	buffer.WriteString("CREATE TABLE ")
+	buffer.WriteEscape(temporary)
+	buffer.WriteString(" (")
+	for i, def := range definitions {
+		if i > 0 {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteString(strings.Join(def, " "))
+	}
+	buffer.WriteByte(')')
+	if len(suffix) > 0 {
+		buffer.WriteByte(' ')
+		buffer.WriteString(strings.Join(suffix, " "))
+	}
+	buffer.WriteByte(';')
+	statements = append(statements, buffer.String())
+
+	columns := definitions.columns()
+	buffer.Reset()
+	buffer.WriteString("INSERT INTO ")
+	buffer.WriteEscape(temporary)
+	buffer.WriteString(" (")
+	for i, column := range columns {
+		if i > 0 {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteEscape(column)
+	}
+	buffer.WriteString(") SELECT ")
+	for i, column := range columns {
+		if i > 0 {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteEscape(column)
+	}
+	buffer.WriteString(" FROM ")
+	buffer.WriteEscape(current.Name)
+	buffer.WriteByte(';')
+	statements = append(statements, buffer.String())
+
+	statements = append(statements,
+		tableBuilder.Build(dbm.Table{Op: dbm.SchemaDrop, Name: current.Name}),
+		tableBuilder.Build(dbm.Table{Op: dbm.SchemaRename, Name: temporary, Rename: current.Name}),
+	)
+
+	return append(statements, current.Statements...), nil
+}
+
+// sqlite3Definitions are the columns and table constraints of a create table statement, each as a list of tokens.
+type sqlite3Definitions [][]string
+
+// parseSQLite3Table splits a create table statement into its definitions and the table options that follow them.
+func parseSQLite3Table(sql string) (sqlite3Definitions, []string, bool) {
+	var (
+		tokens      = sqlite3Tokens(sql)
+		definitions sqlite3Definitions
+	)
+
+	for i, token := range tokens {
+		if token[0] != '(' {
+			continue
+		}
+
+		var def []string
+		for _, token := range sqlite3Tokens(token[1 : len(token)-1]) {
+			if token == "," {
+				definitions = append(definitions, def)
+				def = nil
+			} else {
+				def = append(def, token)
+			}
+		}
+
+		return append(definitions, def), tokens[i+1:], len(def) > 0
+	}
+
+	return nil, nil, false
+}
+
+func (d sqlite3Definitions) columns() []string {
+	var columns []string
+	for _, def := range d {
+		if !isSQLite3Constraint(def) {
+			columns = append(columns, unquoteSQLite3(def[0]))
+		}
+	}
+	return columns
+}
+
+// column returns index of column definition by name.
+func (d sqlite3Definitions) column(name string) int {
+	for i, def := range d {
+		if !isSQLite3Constraint(def) && strings.EqualFold(unquoteSQLite3(def[0]), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// constraint returns index of table constraint by name.
+func (d sqlite3Definitions) constraint(name string) int {
+	for i, def := range d {
+		if len(def) > 1 && strings.EqualFold(def[0], "CONSTRAINT") && strings.EqualFold(unquoteSQLite3(def[1]), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d sqlite3Definitions) alterColumn(tableBuilder builder.Table, change dbm.Column) error {
+	i := d.column(change.Name)
+	if i < 0 {
+		return fmt.Errorf("column `%s` not found", change.Name)
+	}
+
+	var (
+		def    = d[i]
+		result []string
+	)
+
+	switch change.Change {
+	case dbm.ChangeDefinition:
+		buffer := tableBuilder.BufferFactory.Create()
+		change.Op = dbm.SchemaCreate
+
+		// inline primary key is kept as is, including its autoincrement.
+		start, end := sqlite3PrimaryKeyClause(def)
+		if start >= 0 {
+			change.Primary = false
+		}
+
+		tableBuilder.WriteColumn(&buffer, change)
+		result = sqlite3Tokens(buffer.String())
+		if start >= 0 {
+			result = append(result, def[start:end]...)
+		}
+	case dbm.ChangeRequired:
+		for j := 0; j < len(def); j++ {
+			switch {
+			case strings.EqualFold(def[j], "NOT") && j+1 < len(def) && strings.EqualFold(def[j+1], "NULL"):
+				j = sqlite3SkipConflictClause(def, j+2) - 1
+			case strings.EqualFold(def[j], "NULL") && !strings.EqualFold(def[j-1], "DEFAULT"):
+				j = sqlite3SkipConflictClause(def, j+1) - 1
+			default:
+				result = append(result, def[j])
+			}
+		}
+
+		if change.Required {
+			result = append(result, "NOT", "NULL")
+		}
+	case dbm.ChangeDefault:
+		for j := 0; j < len(def); j++ {
+			if strings.EqualFold(def[j], "DEFAULT") && j+1 < len(def) {
+				j++
+				if (def[j] == "-" || def[j] == "+") && j+1 < len(def) {
+					j++
+				}
+				continue
+			}
+			result = append(result, def[j])
+		}
+
+		if change.Default != nil {
+			buffer := tableBuilder.BufferFactory.Create()
+			tableBuilder.MapColumnType(&change)
+			tableBuilder.WriteDefault(&buffer, change.Default)
+			result = append(append(result, "DEFAULT"), sqlite3Tokens(buffer.String())...)
+		}
+	}
+
+	d[i] = result
+	return nil
+}
+
+func (d sqlite3Definitions) alterKey(tableBuilder builder.Table, change dbm.Key) (sqlite3Definitions, error) {
+	switch change.Op {
+	case dbm.SchemaCreate:
+		buffer := tableBuilder.BufferFactory.Create()
+		tableBuilder.WriteKey(&buffer, change)
+		return append(d, sqlite3Tokens(buffer.String())), nil
+	case dbm.SchemaRename:
+		i := d.constraint(change.Name)
+		if i < 0 {
+			return d, fmt.Errorf("constraint `%s` not found", change.Name)
+		}
+
+		// constraint type follows its name, and only the first word is needed to tell them apart.
+		if typ := strings.Fields(string(change.Type)); len(typ) == 0 || len(d[i]) < 3 || !strings.EqualFold(d[i][2], typ[0]) {
+			return d, fmt.Errorf("constraint `%s` is not %s", change.Name, strings.ToLower(string(change.Type)))
+		}
+
+		def := append([]string(nil), d[i]...)
+		def[1] = tableBuilder.BufferFactory.Quoter.ID(change.Rename)
+		d[i] = def
+		return d, nil
+	case dbm.SchemaDrop:
+		if change.Name != "" {
+			i := d.constraint(change.Name)
+			if i < 0 {
+				return d, fmt.Errorf("constraint `%s` not found", change.Name)
+			}
+			return append(d[:i:i], d[i+1:]...), nil
+		}
+
+		if change.Type != dbm.PrimaryKey {
+			return d, fmt.Errorf("name is required to drop %s", strings.ToLower(string(change.Type)))
+		}
+
+		for i, def := range d {
+			if !isSQLite3Constraint(def) {
+				if start, end := sqlite3PrimaryKeyClause(def); start >= 0 {
+					d[i] = append(def[:start:start], def[end:]...)
+					return d, nil
+				}
+			} else if start, _ := sqlite3PrimaryKeyClause(def); start == 0 {
+				return append(d[:i:i], d[i+1:]...), nil
+			}
+		}
+
+		return d, fmt.Errorf("primary key not found")
+	}
+
+	return d, nil
+}
+
+// sqlite3PrimaryKeyClause returns the range of primary key constraint tokens, or -1 when there's none.
+func sqlite3PrimaryKeyClause(def []string) (int, int) {
+	for i := 0; i+1 < len(def); i++ {
+		if !strings.EqualFold(def[i], "PRIMARY") || !strings.EqualFold(def[i+1], "KEY") {
+			continue
+		}
+
+		var (
+			start = i
+			end   = i + 2
+		)
+
+		if start >= 2 && strings.EqualFold(def[start-2], "CONSTRAINT") {
+			start -= 2
+		}
+
+		for end < len(def) {
+			if strings.EqualFold(def[end], "ASC") || strings.EqualFold(def[end], "DESC") || strings.EqualFold(def[end], "AUTOINCREMENT") || def[end][0] == '(' {
+				end++
+			} else if next := sqlite3SkipConflictClause(def, end); next > end {
+				end = next
+			} else {
+				break
+			}
+		}
+
+		return start, end
+	}
+
+	return -1, -1
+}
+
+// sqlite3SkipConflictClause returns index after ON CONFLICT clause starting at i.
+func sqlite3SkipConflictClause(def []string, i int) int {
+	if i+2 < len(def) && strings.EqualFold(def[i], "ON") && strings.EqualFold(def[i+1], "CONFLICT") {
+		return i + 3
+	}
+	return i
+}
+
+func isSQLite3Constraint(def []string) bool {
+	switch strings.ToUpper(def[0]) {
+	case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+		return true
+	}
+	return false
+}
+
+func unquoteSQLite3(token string) string {
+	if len(token) < 2 {
+		return token
+	}
+
+	switch q := token[0]; q {
+	case '"', '`', '\'':
+		return strings.ReplaceAll(token[1:len(token)-1], string(q)+string(q), string(q))
+	case '[':
+		return token[1 : len(token)-1]
+	}
+	return token
+}
+
+// sqlite3Tokens splits sql into words and symbols, quoted values and parenthesized groups are kept as a single token.
+func sqlite3Tokens(sql string) []string {
+	var tokens []string
+
+	for i := 0; i < len(sql); {
+		switch c := sql[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(sql)
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(sql)
+			}
+		case c == '\'' || c == '"' || c == '`' || c == '[' || c == '(':
+			end := sqlite3TokenEnd(sql, i)
+			tokens = append(tokens, sql[i:end])
+			i = end
+		case isSQLite3Word(c):
+			end := i + 1
+			for end < len(sql) && isSQLite3Word(sql[end]) {
+				end++
+			}
+			tokens = append(tokens, sql[i:end])
+			i = end
+		default:
+			tokens = append(tokens, sql[i:i+1])
+			i++
+		}
+	}
+
+	return tokens
+}
+
+// sqlite3TokenEnd returns the end of quoted or parenthesized token starting at i.
+func sqlite3TokenEnd(sql string, i int) int {
+	switch q := sql[i]; q {
+	case '[':
+		if end := strings.IndexByte(sql[i:], ']'); end >= 0 {
+			return i + end + 1
+		}
+	case '(':
+		for j, depth := i, 0; j < len(sql); {
+			switch sql[j] {
+			case '(':
+				depth++
+				j++
+			case ')':
+				depth--
+				j++
+				if depth == 0 {
+					return j
+				}
+			case '\'', '"', '`', '[':
+				j = sqlite3TokenEnd(sql, j)
+			default:
+				j++
+			}
+		}
+	default:
+		// quotes are escaped by doubling them.
+		for j := i + 1; j < len(sql); j++ {
+			if sql[j] != q {
+				continue
+			}
+			if j+1 < len(sql) && sql[j+1] == q {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+
+	return len(sql)
+}
+
+func isSQLite3Word(c byte) bool {
+	return c == '_' || c == '$' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func queryRows(ctx context.Context, db dbm.Database, query string, scan func(rows *dsql.Rows) error) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
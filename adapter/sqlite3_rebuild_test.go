@@ -0,0 +1,220 @@
+package adapter
+
+import (
+	"context"
+	dsql "database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jiyeyuran/dbm"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSQLite3 mimics how SQLite handles foreign_keys pragma and records executed statements.
+type testSQLite3 struct {
+	*dsql.DB
+	mu          sync.Mutex
+	tables      map[string]string
+	children    []string
+	violations  int
+	foreignKeys bool
+	inTx        bool
+	executed    []string
+}
+
+func newTestSQLite3(tables map[string]string, children ...string) *testSQLite3 {
+	db := &testSQLite3{tables: tables, children: children, foreignKeys: true}
+	db.DB = dsql.OpenDB(testSQLite3Connector{db})
+	return db
+}
+
+func (db *testSQLite3) record(query string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.executed = append(db.executed, query)
+}
+
+type testSQLite3Connector struct {
+	db *testSQLite3
+}
+
+func (c testSQLite3Connector) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c testSQLite3Connector) Driver() driver.Driver                        { return nil }
+
+func (c testSQLite3Connector) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+func (c testSQLite3Connector) Close() error { return nil }
+func (c testSQLite3Connector) Begin() (driver.Tx, error) {
+	c.db.record("BEGIN")
+	c.db.inTx = true
+	return c, nil
+}
+
+func (c testSQLite3Connector) Commit() error {
+	c.db.record("COMMIT")
+	c.db.inTx = false
+	return nil
+}
+
+func (c testSQLite3Connector) Rollback() error {
+	c.db.record("ROLLBACK")
+	c.db.inTx = false
+	return nil
+}
+
+func (c testSQLite3Connector) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch query {
+	case "PRAGMA foreign_keys=OFF;":
+		// no-op inside a transaction.
+		c.db.foreignKeys = c.db.foreignKeys && c.db.inTx
+	case "PRAGMA foreign_keys=ON;":
+		c.db.foreignKeys = true
+	}
+
+	if strings.HasPrefix(query, "DROP TABLE") && c.db.foreignKeys && len(c.db.children) > 0 {
+		return nil, errors.New("FOREIGN KEY constraint failed")
+	}
+
+	c.db.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c testSQLite3Connector) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows := &testSQLite3Rows{}
+
+	switch {
+	case query == "PRAGMA foreign_keys":
+		enabled := int64(0)
+		if c.db.foreignKeys {
+			enabled = 1
+		}
+		rows.values = append(rows.values, enabled)
+	case query == "PRAGMA foreign_key_check":
+		c.db.record(query)
+		for i := 0; i < c.db.violations; i++ {
+			rows.values = append(rows.values, "posts")
+		}
+	case strings.Contains(query, "pragma_foreign_key_list"):
+		for _, child := range c.db.children {
+			rows.values = append(rows.values, child)
+		}
+	case strings.HasPrefix(query, "SELECT sql FROM sqlite_master WHERE type = 'table'"):
+		for name, sql := range c.db.tables {
+			if strings.HasSuffix(query, "'"+name+"'") {
+				rows.values = append(rows.values, sql)
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+type testSQLite3Rows struct {
+	values []driver.Value
+}
+
+func (r *testSQLite3Rows) Columns() []string { return []string{"value"} }
+func (r *testSQLite3Rows) Close() error      { return nil }
+func (r *testSQLite3Rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+func TestSQLite3_RebuildReferenced(t *testing.T) {
+	var (
+		ctx   = context.TODO()
+		table = dbm.Table{Op: dbm.SchemaAlter, Name: "users", Definitions: []dbm.TableDefinition{
+			dbm.Column{Op: dbm.SchemaAlter, Name: "name", Type: dbm.Text},
+		}}
+		db = newTestSQLite3(map[string]string{
+			"users": `CREATE TABLE "users" ("id" INTEGER PRIMARY KEY, "name" VARCHAR(255))`,
+			"posts": `CREATE TABLE "posts" ("id" INTEGER PRIMARY KEY, "user_id" INTEGER REFERENCES "users" ("id") ON DELETE CASCADE)`,
+		}, "posts")
+	)
+
+	executed, err := SQLite3.Executor(ctx, db, table)
+	assert.True(t, executed)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		`PRAGMA foreign_keys=OFF;`,
+		`BEGIN`,
+		`CREATE TABLE "dbm_rebuild_users" ("id" INTEGER PRIMARY KEY, "name" TEXT);`,
+		`INSERT INTO "dbm_rebuild_users" ("id", "name") SELECT "id", "name" FROM "users";`,
+		`DROP TABLE "users";`,
+		`ALTER TABLE "dbm_rebuild_users" RENAME TO "users";`,
+		`PRAGMA foreign_key_check`,
+		`COMMIT`,
+		`PRAGMA foreign_keys=ON;`,
+	}, db.executed)
+	assert.True(t, db.foreignKeys)
+
+	t.Run("violations", func(t *testing.T) {
+		db.executed, db.violations = nil, 1
+
+		_, err := SQLite3.Executor(ctx, db, table)
+		assert.Equal(t, errors.New("dbm: rebuild of table `users` violates 1 foreign key constraints"), err)
+		assert.Equal(t, "ROLLBACK", db.executed[len(db.executed)-2])
+		assert.Equal(t, "PRAGMA foreign_keys=ON;", db.executed[len(db.executed)-1])
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		db.executed, db.violations = nil, 0
+
+		tx, err := db.BeginTx(ctx, nil)
+		assert.Nil(t, err)
+		defer tx.Rollback()
+
+		_, err = SQLite3.Executor(ctx, tx, table)
+		assert.Equal(t, errors.New("dbm: table `users` is referenced by foreign keys and can't be rebuilt inside a transaction, use Schema.NoTransaction"), err)
+		assert.Equal(t, []string{`BEGIN`, `PRAGMA foreign_keys=OFF;`}, db.executed)
+	})
+}
+
+func TestSQLite3_FullTextIndexWithoutRowID(t *testing.T) {
+	var (
+		ctx = context.TODO()
+		db  = newTestSQLite3(map[string]string{
+			"posts":    `CREATE TABLE "posts" ("id" INTEGER PRIMARY KEY, "body" TEXT)`,
+			"comments": `CREATE TABLE "comments" ("id" INTEGER PRIMARY KEY, "body" TEXT) WITHOUT ROWID`,
+		})
+	)
+
+	executed, err := SQLite3.Executor(ctx, db, dbm.Index{Op: dbm.SchemaCreate, Table: "posts", Name: "posts_search", Columns: []string{"body"}, FullText: true})
+	assert.False(t, executed)
+	assert.Nil(t, err)
+
+	_, err = SQLite3.Executor(ctx, db, dbm.Index{Op: dbm.SchemaCreate, Table: "comments", Name: "comments_search", Columns: []string{"body"}, FullText: true})
+	assert.EqualError(t, err, "dbm: full-text index `comments_search` requires table `comments` to have a rowid")
+}
+
+func TestSQLite3_ResetIdentityWithoutAutoincrement(t *testing.T) {
+	var (
+		ctx = context.TODO()
+		db  = newTestSQLite3(map[string]string{
+			"invoices": `CREATE TABLE "invoices" ("id" INTEGER PRIMARY KEY AUTOINCREMENT)`,
+			"payments": `CREATE TABLE "payments" ("id" INTEGER PRIMARY KEY)`,
+		})
+		reset = func(name string) dbm.Table {
+			return dbm.Table{Op: dbm.SchemaAlter, Name: name, Definitions: []dbm.TableDefinition{dbm.IdentityReset{Value: 5000}}}
+		}
+	)
+
+	executed, err := SQLite3.Executor(ctx, db, reset("invoices"))
+	assert.False(t, executed)
+	assert.Nil(t, err)
+
+	_, err = SQLite3.Executor(ctx, db, reset("payments"))
+	assert.EqualError(t, err, "dbm: identity of table `payments` can't be reset as it has no AUTOINCREMENT column, use ID with Identity option")
+
+	_, err = SQLite3.Executor(ctx, db, reset("missing"))
+	assert.EqualError(t, err, "dbm: identity of table `missing` can't be reset as it has no AUTOINCREMENT column, use ID with Identity option")
+}
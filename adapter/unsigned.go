@@ -0,0 +1,66 @@
+package adapter
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jiyeyuran/dbm"
+	"github.com/jiyeyuran/dbm/adapter/sql"
+)
+
+// UnsignedMode of adapters without native unsigned integer types.
+type UnsignedMode uint8
+
+const (
+	// UnsignedIgnore keeps the default behavior of adapter, negative numbers are accepted by unsigned columns.
+	UnsignedIgnore UnsignedMode = iota
+	// UnsignedCheck rejects negative numbers of unsigned columns using CHECK (col >= 0) constraint.
+	UnsignedCheck
+	// UnsignedWiden rejects negative numbers like UnsignedCheck and uses the next larger integer type,
+	// so the unsigned range still fits. Unsigned 8 byte integers are rejected as there's no larger integer type.
+	UnsignedWiden
+)
+
+// emulatesUnsigned returns true when the unsigned attribute of column is replaced with a CHECK constraint by mode.
+func emulatesUnsigned(column dbm.Column, mode UnsignedMode) bool {
+	if !column.Unsigned || mode == UnsignedIgnore {
+		return false
+	}
+
+	switch column.Type {
+	case dbm.SmallInt, dbm.Int, dbm.BigInt:
+		return true
+	default:
+		return false
+	}
+}
+
+// emulateUnsigned replaces unsigned attribute of integer column with a CHECK constraint according to mode.
+// The constraint is written as column option, which is only part of created columns, altered columns must write it separately.
+func emulateUnsigned(column *dbm.Column, mode UnsignedMode, id func(string) string) {
+	if !emulatesUnsigned(*column, mode) {
+		return
+	}
+
+	if mode == UnsignedWiden {
+		column.Limit = sql.IntegerSize(*column) + 1
+		column.Precision = 0
+	}
+
+	column.Unsigned = false
+	column.Options = strings.TrimSpace(unsignedCheck(*column, id) + " " + column.Options)
+}
+
+// unsignedCheck constraint that rejects negative numbers of column.
+func unsignedCheck(column dbm.Column, id func(string) string) string {
+	return "CHECK (" + id(column.Name) + " >= 0)"
+}
+
+// validateUnsigned rejects unsigned integer columns that can't be emulated by mode.
+func validateUnsigned(column dbm.Column, mode UnsignedMode) error {
+	if mode == UnsignedWiden && emulatesUnsigned(column, mode) && sql.IntegerSize(column) == 8 {
+		return errors.New("unsigned 8 byte integer can't be widened, use UnsignedCheck or a decimal column")
+	}
+
+	return nil
+}
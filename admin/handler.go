@@ -0,0 +1,243 @@
+// Package admin exposes migration status and control over HTTP.
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jiyeyuran/dbm"
+)
+
+// Authorizer decides whether a request may run mutating operations.
+// Returning an error rejects the request with 403 Forbidden.
+type Authorizer func(r *http.Request) error
+
+// VersionStatus response item.
+type VersionStatus struct {
+	Version   int        `json:"version"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Up        string     `json:"up"`
+	Down      string     `json:"down"`
+}
+
+// Step response item.
+type Step struct {
+	Version     int    `json:"version,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Direction   string `json:"direction"`
+	Description string `json:"description"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler serves the following JSON endpoints relative to its mount point:
+//
+//	GET  /status               status of every registered version.
+//	GET  /plan?version=N       steps needed to reach version N, defaults to latest.
+//	POST /migrate?version=N    migrate up to version N, defaults to latest.
+//	POST /rollback?version=N   rollback versions newer than N, defaults to one step.
+//
+// Mutating endpoints are rejected unless the Authorizer accepts the request,
+// runs are serialized within the process and go through the migration locker.
+type Handler struct {
+	migration *dbm.Migration
+	authorize Authorizer
+	mu        sync.Mutex
+}
+
+// NewHandler wraps migration, a nil authorize rejects every mutating request.
+func NewHandler(migration *dbm.Migration, authorize Authorizer) *Handler {
+	return &Handler{
+		migration: migration,
+		authorize: authorize,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch strings.Trim(r.URL.Path, "/") {
+	case "status":
+		h.serveStatus(w, r)
+	case "plan":
+		h.servePlan(w, r)
+	case "migrate":
+		h.serveMigrate(w, r)
+	case "rollback":
+		h.serveRollback(w, r)
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+func (h *Handler) serveStatus(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.writeStatus(w, r)
+}
+
+func (h *Handler) servePlan(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	target, err := versionParam(r, math.MaxInt)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	steps, err := h.migration.Plan(r.Context(), target)
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+
+	result := make([]Step, len(steps))
+	for i, step := range steps {
+		result[i] = Step{Version: step.Version, Name: step.Name, Direction: "up", Description: step.Description}
+		if step.Rollback {
+			result[i].Direction = "down"
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"steps": result})
+}
+
+func (h *Handler) serveMigrate(w http.ResponseWriter, r *http.Request) {
+	if !h.allowMutation(w, r) {
+		return
+	}
+
+	target, err := versionParam(r, math.MaxInt)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.migration.MigrateTo(r.Context(), target); err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	h.writeStatus(w, r)
+}
+
+func (h *Handler) serveRollback(w http.ResponseWriter, r *http.Request) {
+	if !h.allowMutation(w, r) {
+		return
+	}
+
+	target, err := versionParam(r, -1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if target < 0 {
+		err = h.migration.Rollback(r.Context())
+	} else {
+		err = h.migration.RollbackTo(r.Context(), target)
+	}
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	h.writeStatus(w, r)
+}
+
+func (h *Handler) writeStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.migration.Status(r.Context())
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+
+	result := make([]VersionStatus, len(status))
+	for i, v := range status {
+		result[i] = VersionStatus{Version: v.Version, Applied: v.Applied, Up: v.Up, Down: v.Down}
+		if v.Applied && !v.AppliedAt.IsZero() {
+			appliedAt := v.AppliedAt
+			result[i].AppliedAt = &appliedAt
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"versions": result})
+}
+
+func (h *Handler) allowMutation(w http.ResponseWriter, r *http.Request) bool {
+	if !allowMethod(w, r, http.MethodPost) {
+		return false
+	}
+
+	if h.authorize == nil {
+		writeError(w, http.StatusForbidden, errors.New("forbidden"))
+		return false
+	}
+
+	if err := h.authorize(r); err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return false
+	}
+
+	return true
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	return false
+}
+
+func versionParam(r *http.Request, def int) (int, error) {
+	value := r.URL.Query().Get("version")
+	if value == "" {
+		return def, nil
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 0 {
+		return 0, errors.New("invalid version: " + value)
+	}
+	return version, nil
+}
+
+func statusCode(err error) int {
+	if errors.Is(err, dbm.ErrMigrationLocked) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
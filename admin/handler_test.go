@@ -0,0 +1,252 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jiyeyuran/dbm"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDriver keeps the version table in memory and records every other statement.
+type fakeDriver struct {
+	mu       sync.Mutex
+	versions map[int]time.Time
+	executed []string
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return fakeConn{d}, nil
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not implemented") }
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+
+	var version int
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO dbm_schema_versions"):
+		fmt.Sscanf(query, "INSERT INTO dbm_schema_versions(version, created_at, updated_at) VALUES (%d,", &version)
+		c.d.versions[version] = time.Date(2023, 7, 22, 12, 0, 0, 0, time.UTC)
+	case strings.HasPrefix(query, "DELETE FROM dbm_schema_versions"):
+		fmt.Sscanf(query, "DELETE FROM dbm_schema_versions WHERE version=%d", &version)
+		delete(c.d.versions, version)
+	case query == "dbm.Table":
+		// version table creation.
+	default:
+		c.d.executed = append(c.d.executed, query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+
+	rows := &fakeRows{}
+	for version, createdAt := range c.d.versions {
+		rows.values = append(rows.values, []driver.Value{int64(version), int64(version), createdAt, createdAt})
+	}
+	sort.Slice(rows.values, func(i, j int) bool {
+		return rows.values[i][1].(int64) < rows.values[j][1].(int64)
+	})
+	return rows, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "version", "created_at", "updated_at"}
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+type fakeAdapter struct{}
+
+func (fakeAdapter) Build(migration interface{}) string {
+	if raw, ok := migration.(dbm.Raw); ok {
+		return string(raw)
+	}
+	return fmt.Sprintf("%T", migration)
+}
+
+type fakeLocker struct {
+	locked bool
+}
+
+func (l *fakeLocker) Lock(ctx context.Context) error {
+	if l.locked {
+		return dbm.ErrMigrationLocked
+	}
+	return nil
+}
+
+func (l *fakeLocker) Unlock(ctx context.Context) error {
+	return nil
+}
+
+func newTestHandler(authorize Authorizer) (*Handler, *fakeDriver, *fakeLocker) {
+	var (
+		d         = &fakeDriver{versions: map[int]time.Time{}}
+		locker    = &fakeLocker{}
+		db        = sql.OpenDB(fakeConnector{d})
+		migration = dbm.New(fakeAdapter{}, db)
+	)
+
+	migration.UseLocker(locker)
+	migration.Register(1,
+		func(schema *dbm.Schema) { schema.Exec("CREATE TABLE users") },
+		func(schema *dbm.Schema) { schema.Exec("DROP TABLE users") },
+	)
+	migration.Register(2,
+		func(schema *dbm.Schema) { schema.Exec("CREATE TABLE todos") },
+		func(schema *dbm.Schema) { schema.Exec("DROP TABLE todos") },
+	)
+
+	return NewHandler(&migration, authorize), d, locker
+}
+
+type fakeConnector struct {
+	d *fakeDriver
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{c.d}, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return c.d }
+
+func serve(handler http.Handler, method string, target string) (*httptest.ResponseRecorder, map[string]any) {
+	var (
+		rr   = httptest.NewRecorder()
+		body map[string]any
+	)
+
+	handler.ServeHTTP(rr, httptest.NewRequest(method, target, nil))
+	_ = json.Unmarshal(rr.Body.Bytes(), &body)
+	return rr, body
+}
+
+func TestHandler_Status(t *testing.T) {
+	handler, d, _ := newTestHandler(nil)
+	d.versions[1] = time.Date(2023, 7, 22, 12, 0, 0, 0, time.UTC)
+
+	rr, body := serve(handler, http.MethodGet, "/status")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, []any{
+		map[string]any{"version": 1.0, "applied": true, "applied_at": "2023-07-22T12:00:00Z", "up": "execute raw command", "down": "execute raw command"},
+		map[string]any{"version": 2.0, "applied": false, "up": "execute raw command", "down": "execute raw command"},
+	}, body["versions"])
+}
+
+func TestHandler_Plan(t *testing.T) {
+	handler, d, _ := newTestHandler(nil)
+	d.versions[2] = time.Now()
+
+	rr, body := serve(handler, http.MethodGet, "/plan?version=1")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []any{
+		map[string]any{"version": 2.0, "direction": "down", "description": "execute raw command"},
+		map[string]any{"version": 1.0, "direction": "up", "description": "execute raw command"},
+	}, body["steps"])
+
+	rr, _ = serve(handler, http.MethodGet, "/plan?version=abc")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestHandler_Migrate(t *testing.T) {
+	handler, d, _ := newTestHandler(func(r *http.Request) error { return nil })
+
+	rr, _ := serve(handler, http.MethodPost, "/migrate?version=1")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []string{"CREATE TABLE users"}, d.executed)
+
+	rr, _ = serve(handler, http.MethodPost, "/migrate")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []string{"CREATE TABLE users", "CREATE TABLE todos"}, d.executed)
+	assert.Len(t, d.versions, 2)
+}
+
+func TestHandler_Rollback(t *testing.T) {
+	handler, d, _ := newTestHandler(func(r *http.Request) error { return nil })
+	d.versions[1] = time.Now()
+	d.versions[2] = time.Now()
+
+	rr, _ := serve(handler, http.MethodPost, "/rollback")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []string{"DROP TABLE todos"}, d.executed)
+
+	d.versions[2] = time.Now()
+	rr, _ = serve(handler, http.MethodPost, "/rollback?version=0")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []string{"DROP TABLE todos", "DROP TABLE todos", "DROP TABLE users"}, d.executed)
+	assert.Len(t, d.versions, 0)
+}
+
+func TestHandler_Unauthorized(t *testing.T) {
+	handler, d, _ := newTestHandler(nil)
+
+	rr, _ := serve(handler, http.MethodPost, "/migrate")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	handler, d, _ = newTestHandler(func(r *http.Request) error { return errors.New("token required") })
+
+	rr, body := serve(handler, http.MethodPost, "/rollback")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Equal(t, "token required", body["error"])
+	assert.Empty(t, d.executed)
+}
+
+func TestHandler_Locked(t *testing.T) {
+	handler, d, locker := newTestHandler(func(r *http.Request) error { return nil })
+	locker.locked = true
+
+	rr, _ := serve(handler, http.MethodPost, "/migrate")
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Empty(t, d.executed)
+}
+
+func TestHandler_MethodAndPath(t *testing.T) {
+	handler, _, _ := newTestHandler(nil)
+
+	rr, _ := serve(handler, http.MethodGet, "/migrate")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	assert.Equal(t, http.MethodPost, rr.Header().Get("Allow"))
+
+	rr, _ = serve(handler, http.MethodPost, "/status")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	rr, _ = serve(handler, http.MethodGet, "/unknown")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -8,6 +8,8 @@ const (
 	ID ColumnType = "ID"
 	// BigID ColumnType.
 	BigID ColumnType = "BigID"
+	// UUIDID ColumnType, a UUID primary key generated by database.
+	UUIDID ColumnType = "UUIDID"
 	// Bool ColumnType.
 	Bool ColumnType = "BOOL"
 	// SmallInt ColumnType.
@@ -22,6 +24,8 @@ const (
 	Decimal ColumnType = "DECIMAL"
 	// String ColumnType.
 	String ColumnType = "STRING"
+	// Char ColumnType, fixed length string of Limit characters.
+	Char ColumnType = "CHAR"
 	// Text ColumnType.
 	Text ColumnType = "TEXT"
 	// JSON ColumnType that will fallback to Text ColumnType if adapter does not support it.
@@ -32,11 +36,51 @@ const (
 	DateTime ColumnType = "DATETIME"
 	// Time ColumnType.
 	Time ColumnType = "TIME"
+	// UUID ColumnType.
+	UUID ColumnType = "UUID"
+	// Binary ColumnType, variable length binary data up to Limit bytes.
+	Binary ColumnType = "BINARY"
+	// Blob ColumnType, large binary data.
+	Blob ColumnType = "BLOB"
+	// Enum ColumnType, a string limited to the predefined Values.
+	Enum ColumnType = "ENUM"
+)
+
+// Expr is an sql expression used as column default, it's written as is instead of as a quoted value.
+type Expr string
+
+const (
+	// ExprNow is the current date and time, translated to the equivalent function by each adapter.
+	ExprNow Expr = "CURRENT_TIMESTAMP"
+	// ExprUUID generates a random UUID, translated to the equivalent function by each adapter.
+	ExprUUID Expr = "UUID()"
+)
+
+// ColumnIdentity defines how values of an identity column are generated.
+// Start and Increment use the default of database when zero.
+type ColumnIdentity struct {
+	// Always rejects explicit values, which are allowed by default.
+	Always    bool
+	Start     int
+	Increment int
+}
+
+// ColumnChange defines which part of an existing column is altered.
+type ColumnChange uint8
+
+const (
+	// ChangeDefinition changes type, nullability and default of the column.
+	ChangeDefinition ColumnChange = iota
+	// ChangeRequired sets or drops NOT NULL of the column.
+	ChangeRequired
+	// ChangeDefault sets or drops the default of the column.
+	ChangeDefault
 )
 
 // Column definition.
 type Column struct {
 	Op        SchemaOp
+	Change    ColumnChange
 	Name      string
 	Type      ColumnType
 	Rename    string
@@ -48,7 +92,24 @@ type Column struct {
 	Precision int
 	Scale     int
 	Default   any
-	Options   string
+	// Values allowed in an enum column.
+	Values []string
+	// TypeName of the database type created for the column, PostgreSQL enum types are named <table>_<column>.
+	TypeName string
+	// Identity generates values of integer column using a sequence.
+	Identity *ColumnIdentity
+	// Generated expression that computes the column, the value is stored when Stored is true.
+	Generated string
+	Stored    bool
+	// WithoutTimezone stores DateTime as a timestamp without time zone, PostgreSQL and MSSQL are time zone aware by default.
+	WithoutTimezone bool
+	// AutoUpdate sets the datetime column to the current timestamp whenever the row is updated, only supported by MySQL.
+	AutoUpdate bool
+	Using      string
+	Charset    string
+	Collation  string
+	Comment    string
+	Options    string
 }
 
 func (Column) internalTableDefinition() {}
@@ -84,3 +145,29 @@ func dropColumn(name string, options []ColumnOption) Column {
 	applyColumnOptions(&column, options)
 	return column
 }
+
+func changeColumn(name string, typ ColumnType, options []ColumnOption) Column {
+	column := Column{
+		Op:     SchemaAlter,
+		Change: ChangeDefinition,
+		Name:   name,
+		Type:   typ,
+	}
+
+	applyColumnOptions(&column, options)
+	return column
+}
+
+func changeColumnRequired(name string, typ ColumnType, required bool, options []ColumnOption) Column {
+	column := changeColumn(name, typ, options)
+	column.Change = ChangeRequired
+	column.Required = required
+	return column
+}
+
+func changeColumnDefault(name string, typ ColumnType, def any, options []ColumnOption) Column {
+	column := changeColumn(name, typ, options)
+	column.Change = ChangeDefault
+	column.Default = def
+	return column
+}
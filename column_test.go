@@ -17,6 +17,9 @@ func TestCreateColumn(t *testing.T) {
 			Precision(5),
 			Scale(2),
 			Default(0),
+			Charset("utf8mb4"),
+			Collation("utf8mb4_bin"),
+			Comment("comment"),
 			Options("options"),
 		}
 		column = createColumn("add", Decimal, options)
@@ -33,6 +36,9 @@ func TestCreateColumn(t *testing.T) {
 		Precision: 5,
 		Scale:     2,
 		Default:   0,
+		Charset:   "utf8mb4",
+		Collation: "utf8mb4_bin",
+		Comment:   "comment",
 		Options:   "options",
 	}, column)
 }
@@ -92,6 +98,48 @@ func TestDropColumn(t *testing.T) {
 	}, column)
 }
 
+func TestCreateColumn_defaultExpr(t *testing.T) {
+	assert.Equal(t, Expr("now()"), createColumn("created_at", DateTime, []ColumnOption{DefaultExpr("now()")}).Default)
+	assert.Equal(t, ExprUUID, createColumn("id", String, []ColumnOption{DefaultUUID()}).Default)
+	assert.Equal(t, Column{
+		Name:       "updated_at",
+		Type:       DateTime,
+		Default:    ExprNow,
+		AutoUpdate: true,
+	}, createColumn("updated_at", DateTime, []ColumnOption{DefaultNow(), AutoUpdate(true)}))
+}
+
+func TestCreateColumn_generated(t *testing.T) {
+	assert.Equal(t, Column{
+		Name:      "email_lower",
+		Type:      String,
+		Generated: "lower(email)",
+		Stored:    true,
+	}, createColumn("email_lower", String, []ColumnOption{Generated("lower(email)", true)}))
+}
+
+func TestCreateColumn_identity(t *testing.T) {
+	assert.Equal(t, Column{
+		Name:     "number",
+		Type:     BigInt,
+		Identity: &ColumnIdentity{Always: true, Start: 1000, Increment: 1},
+	}, createColumn("number", BigInt, []ColumnOption{Identity(true, 1000, 1)}))
+}
+
+func TestCreateColumn_withTimezone(t *testing.T) {
+	assert.Equal(t, Column{
+		Name:            "published_at",
+		Type:            DateTime,
+		Precision:       3,
+		WithoutTimezone: true,
+	}, createColumn("published_at", DateTime, []ColumnOption{Precision(3), WithTimezone(false)}))
+
+	assert.Equal(t, Column{
+		Name: "published_at",
+		Type: DateTime,
+	}, createColumn("published_at", DateTime, []ColumnOption{WithTimezone(true)}))
+}
+
 func TestColumn_InternalTableDefinition(t *testing.T) {
 	assert.NotPanics(t, func() { Column{}.internalTableDefinition() })
 }
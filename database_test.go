@@ -0,0 +1,185 @@
+package dbm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// testDatabase is an in memory database that keeps the version tables and records every other statement.
+type testDatabase struct {
+	*sql.DB
+	mu          sync.Mutex
+	versions    map[string]map[int]time.Time
+	repeatables map[string]string
+	executed    []string
+	failOn      string
+}
+
+func newTestDatabase() *testDatabase {
+	db := &testDatabase{versions: map[string]map[int]time.Time{}, repeatables: map[string]string{}}
+	db.DB = sql.OpenDB(testConnector{db})
+	return db
+}
+
+func (db *testDatabase) Executed() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return append([]string(nil), db.executed...)
+}
+
+func (db *testDatabase) table(name string) map[int]time.Time {
+	if db.versions[name] == nil {
+		db.versions[name] = map[int]time.Time{}
+	}
+	return db.versions[name]
+}
+
+type testConnector struct {
+	db *testDatabase
+}
+
+func (c testConnector) Connect(context.Context) (driver.Conn, error) { return testConn(c), nil }
+func (c testConnector) Driver() driver.Driver                        { return nil }
+
+type testConn struct {
+	db *testDatabase
+}
+
+func (c testConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+func (c testConn) Close() error { return nil }
+func (c testConn) Begin() (driver.Tx, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	c.db.executed = append(c.db.executed, "BEGIN")
+	return testTx(c), nil
+}
+
+func (c testConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if c.db.failOn != "" && strings.Contains(query, c.db.failOn) {
+		return nil, errors.New("exec failed: " + query)
+	}
+
+	var (
+		table    string
+		version  int
+		name     string
+		checksum string
+	)
+
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO "+versionTable):
+		table, query, _ = strings.Cut(strings.TrimPrefix(query, "INSERT INTO "), "(")
+		fmt.Sscanf(query, "version, created_at, updated_at) VALUES (%d,", &version)
+		c.db.table(table)[version] = time.Date(2023, 7, 22, 12, 0, 0, 0, time.UTC)
+	case strings.HasPrefix(query, "DELETE FROM "+versionTable):
+		fmt.Sscanf(strings.TrimPrefix(query, "DELETE FROM "), "%s WHERE version=%d", &table, &version)
+		delete(c.db.table(table), version)
+	case strings.HasPrefix(query, "INSERT INTO "+repeatableTable):
+		_, query, _ = strings.Cut(query, "VALUES (")
+		fmt.Sscanf(query, "%s '%64s'", &name, &checksum)
+		c.db.repeatables[strings.Trim(name, "',")] = checksum
+	case strings.HasPrefix(query, "UPDATE "+repeatableTable):
+		_, query, _ = strings.Cut(query, "SET checksum=")
+		fmt.Sscanf(query, "'%64s'", &checksum)
+		_, name, _ = strings.Cut(query, "WHERE name=")
+		c.db.repeatables[strings.Trim(name, "'")] = checksum
+	case strings.Contains(query, versionTable), strings.Contains(query, repeatableTable):
+		// version table creation.
+	default:
+		c.db.executed = append(c.db.executed, query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c testConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	var (
+		rows        = &testRows{columns: []string{"id", "version", "created_at", "updated_at"}}
+		_, table, _ = strings.Cut(query, " FROM ")
+	)
+
+	if strings.HasPrefix(table, repeatableTable) {
+		rows.columns = []string{"name", "checksum"}
+		for name, checksum := range c.db.repeatables {
+			rows.values = append(rows.values, []driver.Value{name, checksum})
+		}
+		return rows, nil
+	}
+
+	table, _, _ = strings.Cut(table, " ")
+	for version, createdAt := range c.db.table(table) {
+		rows.values = append(rows.values, []driver.Value{int64(version), int64(version), createdAt, createdAt})
+	}
+	sort.Slice(rows.values, func(i, j int) bool {
+		return rows.values[i][1].(int64) < rows.values[j][1].(int64)
+	})
+	return rows, nil
+}
+
+// testTx records transaction boundaries, statements are applied immediately.
+type testTx struct {
+	db *testDatabase
+}
+
+func (tx testTx) Commit() error   { return tx.end("COMMIT") }
+func (tx testTx) Rollback() error { return tx.end("ROLLBACK") }
+
+func (tx testTx) end(query string) error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+
+	tx.db.executed = append(tx.db.executed, query)
+	return nil
+}
+
+type testRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *testRows) Columns() []string {
+	return r.columns
+}
+
+func (r *testRows) Close() error { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// testAdapter renders raw migrations as is and everything else by its description.
+type testAdapter struct{}
+
+func (testAdapter) Build(migration interface{}) string {
+	switch v := migration.(type) {
+	case Raw:
+		return string(v)
+	case Table:
+		return v.description()
+	case Index:
+		return v.description()
+	}
+	return ""
+}
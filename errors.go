@@ -9,6 +9,9 @@ var (
 	// ErrNotFound returned when entities not found.
 	ErrNotFound = NotFoundError{}
 
+	// ErrMigrationLocked returned by Locker when another process is running migrations.
+	ErrMigrationLocked = errors.New("dbm: migration is locked by another process")
+
 	// ErrCheckConstraint is an auxiliary variable for error handling.
 	// This is only to be used when checking error with errors.Is(err, ErrCheckConstraint).
 	ErrCheckConstraint = ConstraintError{Type: CheckConstraint}
@@ -106,3 +109,19 @@ func (ce ConstraintError) Error() string {
 
 	return ce.Type.String() + "Error"
 }
+
+// ValidationError returned when a migration contains definitions that can't be built for the database.
+type ValidationError struct {
+	Table   string
+	Name    string
+	Message string
+}
+
+// Error message.
+func (ve ValidationError) Error() string {
+	if ve.Name == "" {
+		return "dbm: invalid definition of " + ve.Table + ": " + ve.Message
+	}
+
+	return "dbm: invalid definition of " + ve.Table + "." + ve.Name + ": " + ve.Message
+}
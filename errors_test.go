@@ -37,6 +37,11 @@ func TestNotFoundError_Is(t *testing.T) {
 	}
 }
 
+func TestValidationError(t *testing.T) {
+	assert.Equal(t, "dbm: invalid definition of users.age: default \"old\" is not a valid int", ValidationError{Table: "users", Name: "age", Message: "default \"old\" is not a valid int"}.Error())
+	assert.Equal(t, "dbm: invalid definition of users: unsupported", ValidationError{Table: "users", Message: "unsupported"}.Error())
+}
+
 func TestConstraintType(t *testing.T) {
 	assert.Equal(t, "CheckConstraint", CheckConstraint.String())
 	assert.Equal(t, "NotNullConstraint", NotNullConstraint.String())
@@ -0,0 +1,156 @@
+package dbm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RegisterDependent registers a migration that depends on other versions and switches the migration into graph mode.
+// In graph mode versions are applied in topological order of their dependencies instead of by version number,
+// versions registered without dependencies are roots of the graph.
+func (m *Migration) RegisterDependent(v int, dependsOn []int, up func(schema *Schema), down func(schema *Schema)) {
+	m.Register(v, up, down)
+	m.versions[len(m.versions)-1].dependsOn = dependsOn
+	m.graph = true
+}
+
+// Merge registers an empty migration that joins multiple heads into a single head.
+func (m *Migration) Merge(v int, heads []int) {
+	m.RegisterDependent(v, heads, func(schema *Schema) {}, func(schema *Schema) {})
+}
+
+// includes returns whether a version is part of the target,
+// in graph mode the target includes itself and all of its ancestors.
+func (m *Migration) includes(target int) (func(v int) bool, error) {
+	if !m.graph {
+		return func(v int) bool { return v <= target }, nil
+	}
+
+	if target == math.MaxInt {
+		if heads := m.versions.heads(); len(heads) > 1 {
+			return nil, fmt.Errorf("dbm: multiple heads: %s", joinVersions(heads))
+		}
+		return func(v int) bool { return true }, nil
+	}
+
+	var (
+		index     = make(map[int]version, len(m.versions))
+		ancestors = map[int]bool{}
+		visit     func(v int)
+	)
+
+	for _, v := range m.versions {
+		index[v.Version] = v
+	}
+
+	if _, ok := index[target]; !ok && target != 0 {
+		return nil, fmt.Errorf("dbm: unknown migration: %d", target)
+	}
+
+	visit = func(v int) {
+		if ancestors[v] {
+			return
+		}
+		ancestors[v] = true
+		for _, dep := range index[v].dependsOn {
+			visit(dep)
+		}
+	}
+
+	if target != 0 {
+		visit(target)
+	}
+
+	return func(v int) bool { return ancestors[v] }, nil
+}
+
+// heads returns versions that no other version depends on.
+func (v versions) heads() []int {
+	var (
+		heads     []int
+		dependent = map[int]bool{}
+	)
+
+	for _, ver := range v {
+		for _, dep := range ver.dependsOn {
+			dependent[dep] = true
+		}
+	}
+
+	for _, ver := range v {
+		if !dependent[ver.Version] {
+			heads = append(heads, ver.Version)
+		}
+	}
+
+	return heads
+}
+
+// topological returns versions sorted by their dependencies, ties are broken by version number.
+func (v versions) topological() (versions, error) {
+	var (
+		result     = make(versions, 0, len(v))
+		index      = make(map[int]int, len(v))
+		indegree   = make(map[int]int, len(v))
+		dependents = make(map[int][]int, len(v))
+		ready      []int
+	)
+
+	for i, ver := range v {
+		index[ver.Version] = i
+	}
+
+	for _, ver := range v {
+		for _, dep := range ver.dependsOn {
+			if _, ok := index[dep]; !ok {
+				return nil, fmt.Errorf("dbm: migration %d depends on unknown migration: %d", ver.Version, dep)
+			}
+			indegree[ver.Version]++
+			dependents[dep] = append(dependents[dep], ver.Version)
+		}
+	}
+
+	for _, ver := range v {
+		if indegree[ver.Version] == 0 {
+			ready = append(ready, ver.Version)
+		}
+	}
+
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		next := ready[0]
+		ready = ready[1:]
+		result = append(result, v[index[next]])
+
+		for _, dependent := range dependents[next] {
+			indegree[dependent]--
+			if indegree[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(result) != len(v) {
+		var cycle []int
+		for _, ver := range v {
+			if indegree[ver.Version] > 0 {
+				cycle = append(cycle, ver.Version)
+			}
+		}
+		sort.Ints(cycle)
+		return nil, fmt.Errorf("dbm: migration dependency cycle: %s", joinVersions(cycle))
+	}
+
+	return result, nil
+}
+
+func joinVersions(versions []int) string {
+	s := make([]string, len(versions))
+	for i := range versions {
+		s[i] = strconv.Itoa(versions[i])
+	}
+	return strings.Join(s, ", ")
+}
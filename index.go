@@ -2,12 +2,33 @@ package dbm
 
 // Index definition.
 type Index struct {
-	Op       SchemaOp
-	Table    string
-	Name     string
-	Unique   bool
-	Columns  []string
+	Op      SchemaOp
+	Table   string
+	Name    string
+	Rename  string
+	Unique  bool
+	Columns []string
+	// Expressions are the entries of Columns written as is instead of escaped.
+	Expressions []string
+	// Ascending and Descending are the entries of Columns with an explicit sort order.
+	Ascending  []string
+	Descending []string
+	// Using is the index method, such as gin.
+	Using string
+	// Include lists non-key columns stored in the index.
+	Include []string
+	// Where is the predicate of a partial index.
+	Where string
+	// Concurrently builds or drops the index without blocking writes to the table.
+	Concurrently bool
+	// FullText index for text search over Columns.
+	FullText bool
+	// Language of full-text index, used as text search configuration by PostgreSQL and word breaker by MSSQL.
+	Language string
+	// Catalog of full-text index, only used by MSSQL.
+	Catalog  string
 	Optional bool
+	Comment  string
 	Options  string
 }
 
@@ -17,6 +38,26 @@ func (i Index) description() string {
 
 func (Index) internalMigration() {}
 
+// HasColumn returns true when col is one of the index columns or expressions.
+func (i Index) HasColumn(col string) bool {
+	return contains(i.Columns, col)
+}
+
+// IsExpression returns true when col is an expression written as is.
+func (i Index) IsExpression(col string) bool {
+	return contains(i.Expressions, col)
+}
+
+// IsAscending returns true when col is explicitly sorted in ascending order.
+func (i Index) IsAscending(col string) bool {
+	return contains(i.Ascending, col)
+}
+
+// IsDescending returns true when col is sorted in descending order.
+func (i Index) IsDescending(col string) bool {
+	return contains(i.Descending, col)
+}
+
 func createIndex(table string, name string, columns []string, options []IndexOption) Index {
 	index := Index{
 		Op:      SchemaCreate,
@@ -35,6 +76,12 @@ func createUniqueIndex(table string, name string, columns []string, options []In
 	return index
 }
 
+func createFullTextIndex(table string, name string, columns []string, options []IndexOption) Index {
+	index := createIndex(table, name, columns, options)
+	index.FullText = true
+	return index
+}
+
 func dropIndex(table string, name string, options []IndexOption) Index {
 	index := Index{
 		Op:    SchemaDrop,
@@ -46,8 +93,26 @@ func dropIndex(table string, name string, options []IndexOption) Index {
 	return index
 }
 
+func dropFullTextIndex(table string, name string, options []IndexOption) Index {
+	index := dropIndex(table, name, options)
+	index.FullText = true
+	return index
+}
+
+func renameIndex(table string, name string, newName string, options []IndexOption) Index {
+	index := Index{
+		Op:     SchemaRename,
+		Table:  table,
+		Name:   name,
+		Rename: newName,
+	}
+
+	applyIndexOptions(&index, options)
+	return index
+}
+
 // IndexOption interface.
-// Available options are: Comment, Options.
+// Available options are: Unique, Expression, Asc, Desc, Using, Include, Where, Concurrently, Language, Catalog, Optional, Comment, Options.
 type IndexOption interface {
 	applyIndex(index *Index)
 }
@@ -58,9 +123,19 @@ func applyIndexOptions(index *Index, options []IndexOption) {
 	}
 }
 
-// Name option for defining custom index name.
+// Name option for defining custom key name.
 type Name string
 
 func (n Name) applyKey(key *Key) {
 	key.Name = string(n)
 }
+
+func contains(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+
+	return false
+}
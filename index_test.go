@@ -11,6 +11,7 @@ func TestCreateIndex(t *testing.T) {
 		options = []IndexOption{
 			Options("options"),
 			Optional(true),
+			Comment("comment"),
 		}
 		index = createIndex("table", "add_idx", []string{"add"}, options)
 	)
@@ -20,10 +21,45 @@ func TestCreateIndex(t *testing.T) {
 		Name:     "add_idx",
 		Columns:  []string{"add"},
 		Optional: true,
+		Comment:  "comment",
 		Options:  "options",
 	}, index)
 }
 
+func TestCreateIndex_features(t *testing.T) {
+	var (
+		options = []IndexOption{
+			Expression("lower(email)", "id"),
+			Desc("id"),
+			Asc("tenant_id"),
+			Using("btree"),
+			Include("name"),
+			Where("deleted_at IS NULL"),
+		}
+		index = createIndex("users", "users_email", []string{"tenant_id", "id"}, options)
+	)
+
+	assert.Equal(t, Index{
+		Table:       "users",
+		Name:        "users_email",
+		Columns:     []string{"tenant_id", "id", "lower(email)"},
+		Expressions: []string{"lower(email)", "id"},
+		Ascending:   []string{"tenant_id"},
+		Descending:  []string{"id"},
+		Using:       "btree",
+		Include:     []string{"name"},
+		Where:       "deleted_at IS NULL",
+	}, index)
+
+	assert.True(t, index.HasColumn("lower(email)"))
+	assert.False(t, index.HasColumn("name"))
+	assert.True(t, index.IsExpression("id"))
+	assert.False(t, index.IsExpression("tenant_id"))
+	assert.True(t, index.IsAscending("tenant_id"))
+	assert.True(t, index.IsDescending("id"))
+	assert.False(t, index.IsDescending("tenant_id"))
+}
+
 func TestCreateUniqueIndex(t *testing.T) {
 	var (
 		options = []IndexOption{
@@ -41,6 +77,35 @@ func TestCreateUniqueIndex(t *testing.T) {
 	}, index)
 }
 
+func TestDropFullTextIndex(t *testing.T) {
+	assert.Equal(t, Index{
+		Op:       SchemaDrop,
+		Table:    "posts",
+		Name:     "posts_search",
+		FullText: true,
+	}, dropFullTextIndex("posts", "posts_search", nil))
+}
+
+func TestRenameIndex(t *testing.T) {
+	assert.Equal(t, Index{
+		Op:     SchemaRename,
+		Table:  "table",
+		Name:   "old_idx",
+		Rename: "new_idx",
+	}, renameIndex("table", "old_idx", "new_idx", nil))
+}
+
+func TestCreateFullTextIndex(t *testing.T) {
+	assert.Equal(t, Index{
+		Table:    "posts",
+		Name:     "posts_search",
+		Columns:  []string{"title", "body"},
+		FullText: true,
+		Language: "english",
+		Catalog:  "search",
+	}, createFullTextIndex("posts", "posts_search", []string{"title", "body"}, []IndexOption{Language("english"), Catalog("search")}))
+}
+
 func TestDropIndex(t *testing.T) {
 	var (
 		options = []IndexOption{
@@ -10,6 +10,8 @@ const (
 	ForeignKey KeyType = "FOREIGN KEY"
 	// UniqueKey KeyType.
 	UniqueKey = "UNIQUE"
+	// CheckKey KeyType.
+	CheckKey KeyType = "CHECK"
 )
 
 // ForeignKeyReference definition.
@@ -18,6 +20,12 @@ type ForeignKeyReference struct {
 	Columns  []string
 	OnDelete string
 	OnUpdate string
+	// MatchFull requires either all or none of the columns to be null.
+	MatchFull bool
+	// Deferrable allows the check to be deferred until the end of the transaction.
+	Deferrable bool
+	// InitiallyDeferred defers the check by default, it implies Deferrable.
+	InitiallyDeferred bool
 }
 
 // Key definition.
@@ -28,7 +36,9 @@ type Key struct {
 	Columns   []string
 	Rename    string
 	Reference ForeignKeyReference
-	Options   string
+	// Expression of check constraint.
+	Expression string
+	Options    string
 }
 
 func (Key) internalTableDefinition() {}
@@ -49,13 +59,17 @@ func createPrimaryKeys(columns []string, options []KeyOption) Key {
 }
 
 func createForeignKey(column string, refTable string, refColumn string, options []KeyOption) Key {
+	return createForeignKeys([]string{column}, refTable, []string{refColumn}, options)
+}
+
+func createForeignKeys(columns []string, refTable string, refColumns []string, options []KeyOption) Key {
 	key := Key{
 		Op:      SchemaCreate,
 		Type:    ForeignKey,
-		Columns: []string{column},
+		Columns: columns,
 		Reference: ForeignKeyReference{
 			Table:   refTable,
-			Columns: []string{refColumn},
+			Columns: refColumns,
 		},
 	}
 
@@ -63,4 +77,37 @@ func createForeignKey(column string, refTable string, refColumn string, options
 	return key
 }
 
-// TODO: Rename and Drop, PR welcomed.
+func createCheck(name string, expr string, options []KeyOption) Key {
+	key := Key{
+		Op:         SchemaCreate,
+		Name:       name,
+		Type:       CheckKey,
+		Expression: expr,
+	}
+
+	applyKeyOptions(&key, options)
+	return key
+}
+
+func dropKey(name string, typ KeyType, options []KeyOption) Key {
+	key := Key{
+		Op:   SchemaDrop,
+		Name: name,
+		Type: typ,
+	}
+
+	applyKeyOptions(&key, options)
+	return key
+}
+
+func renameKey(name string, newName string, typ KeyType, options []KeyOption) Key {
+	key := Key{
+		Op:     SchemaRename,
+		Name:   name,
+		Type:   typ,
+		Rename: newName,
+	}
+
+	applyKeyOptions(&key, options)
+	return key
+}
@@ -31,6 +31,31 @@ func TestCreateForeignKey(t *testing.T) {
 	}, index)
 }
 
+func TestCreateForeignKeys(t *testing.T) {
+	var (
+		options = []KeyOption{
+			Name("orders_product_fk"),
+			MatchFull(true),
+			Deferrable(true),
+			InitiallyDeferred(true),
+		}
+		key = createForeignKeys([]string{"product_id", "variant_id"}, "variants", []string{"product_id", "id"}, options)
+	)
+
+	assert.Equal(t, Key{
+		Type:    ForeignKey,
+		Name:    "orders_product_fk",
+		Columns: []string{"product_id", "variant_id"},
+		Reference: ForeignKeyReference{
+			Table:             "variants",
+			Columns:           []string{"product_id", "id"},
+			MatchFull:         true,
+			Deferrable:        true,
+			InitiallyDeferred: true,
+		},
+	}, key)
+}
+
 func TestCreateUniqueKey(t *testing.T) {
 	var (
 		options = []KeyOption{
@@ -2,7 +2,9 @@ package dbm
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"math"
 	"sort"
 	"time"
 )
@@ -18,9 +20,10 @@ type version struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
-	up      Schema
-	down    Schema
-	applied bool
+	up        Schema
+	down      Schema
+	dependsOn []int
+	applied   bool
 }
 
 func (version) Table() string {
@@ -41,13 +44,80 @@ func (v versions) Swap(i, j int) {
 	v[i], v[j] = v[j], v[i]
 }
 
+// Locker serializes migration runs across processes, e.g. using database advisory locks.
+// Lock should return ErrMigrationLocked when the lock is held by someone else.
+type Locker interface {
+	Lock(ctx context.Context) error
+	Unlock(ctx context.Context) error
+}
+
+// Transactor is a Database able to begin transactions, such as *sql.DB.
+type Transactor interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// VersionStatus describes a registered migration version.
+type VersionStatus struct {
+	Version   int
+	Applied   bool
+	AppliedAt time.Time
+	Up        string
+	Down      string
+}
+
+// Step is a single planned migration step, repeatable migrations are identified by name.
+type Step struct {
+	Version     int
+	Name        string
+	Rollback    bool
+	Description string
+}
+
 // Migration utility that handles migration logic.
 type Migration struct {
-	db                 Database
-	adapter            Adapter
-	versions           versions
-	versionTableExists bool
-	panicOnError       bool
+	db                    Database
+	adapter               Adapter
+	locker                Locker
+	transaction           bool
+	namespace             string
+	graph                 bool
+	versions              versions
+	versionTableExists    bool
+	repeatables           []repeatable
+	repeatableTableExists bool
+	panicOnError          bool
+}
+
+// UseNamespace keeps the version history of this migration separate from other namespaces sharing the database.
+// Each namespace is tracked in its own dbm_schema_versions_<namespace> table,
+// the namespace may only contain letters, digits and underscores.
+func (m *Migration) UseNamespace(namespace string) {
+	if err := validateNamespace(namespace); err != nil {
+		panic(err.Error())
+	}
+
+	m.namespace = namespace
+	m.versionTableExists = false
+}
+
+func validateNamespace(namespace string) error {
+	for _, c := range namespace {
+		if !(c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')) {
+			return fmt.Errorf("dbm: invalid namespace `%s`", namespace)
+		}
+	}
+	return nil
+}
+
+// UseLocker sets the locker acquired around every migrate and rollback run.
+func (m *Migration) UseLocker(locker Locker) {
+	m.locker = locker
+}
+
+// UseTransaction runs every version in its own transaction when the database is a Transactor,
+// except versions whose schema calls NoTransaction.
+func (m *Migration) UseTransaction(transaction bool) {
+	m.transaction = transaction
 }
 
 // Register a migration.
@@ -60,9 +130,16 @@ func (m *Migration) Register(v int, up func(schema *Schema), down func(schema *S
 	m.versions = append(m.versions, version{Version: v, up: upSchema, down: downSchema})
 }
 
+func (m Migration) versionTable() string {
+	if m.namespace == "" {
+		return versionTable
+	}
+	return versionTable + "_" + m.namespace
+}
+
 func (m Migration) buildVersionTableDefinition() Table {
 	var schema Schema
-	schema.CreateTableIfNotExists(versionTable, func(t *Table) {
+	schema.CreateTableIfNotExists(m.versionTable(), func(t *Table) {
 		t.ID("id")
 		t.BigInt("version", Unsigned(true), Unique(true))
 		t.DateTime("created_at")
@@ -79,12 +156,12 @@ func (m *Migration) sync(ctx context.Context) error {
 	)
 
 	if !m.versionTableExists {
-		if err := m.run(ctx, m.buildVersionTableDefinition()); err != nil {
+		if err := m.run(ctx, m.db, m.buildVersionTableDefinition()); err != nil {
 			return err
 		}
 		m.versionTableExists = true
 	}
-	sqlstr := "SELECT id, version, created_at, updated_at FROM " + versionTable + " ORDER BY version"
+	sqlstr := "SELECT id, version, created_at, updated_at FROM " + m.versionTable() + " ORDER BY version"
 	rows, err := m.db.QueryContext(ctx, sqlstr)
 	if err != nil {
 		return m.check(err)
@@ -104,6 +181,8 @@ func (m *Migration) sync(ctx context.Context) error {
 	for i := range m.versions {
 		if vi < len(versions) && m.versions[i].Version == versions[vi].Version {
 			m.versions[i].ID = versions[vi].ID
+			m.versions[i].CreatedAt = versions[vi].CreatedAt
+			m.versions[i].UpdatedAt = versions[vi].UpdatedAt
 			m.versions[i].applied = true
 			vi++
 		} else {
@@ -114,6 +193,14 @@ func (m *Migration) sync(ctx context.Context) error {
 	if vi != len(versions) {
 		return m.check(fmt.Errorf("dbm: missing local migration: %d", versions[vi].Version))
 	}
+
+	if m.graph {
+		sorted, err := m.versions.topological()
+		if err != nil {
+			return m.check(err)
+		}
+		m.versions = sorted
+	}
 	return nil
 }
 
@@ -123,68 +210,278 @@ func (m *Migration) MustMigrate(ctx context.Context) {
 	m.panicOnError = false
 }
 
-// Migrate to the latest schema version.
+// Migrate to the latest schema version, followed by changed repeatable migrations.
 func (m *Migration) Migrate(ctx context.Context) error {
-	if err := m.sync(ctx); err != nil {
-		return err
-	}
+	return m.MigrateTo(ctx, math.MaxInt)
+}
 
-	for _, v := range m.versions {
-		if v.applied {
-			continue
+// MigrateTo applies all pending versions up to and including the target version.
+// In graph mode the target and all of its ancestors are applied.
+func (m *Migration) MigrateTo(ctx context.Context, target int) error {
+	return m.locked(ctx, func() error {
+		if err := m.sync(ctx); err != nil {
+			return err
 		}
-		now := time.Now().Truncate(time.Microsecond).Format(timeLayout)
-		sqlstr := fmt.Sprintf("INSERT INTO %s(version, created_at, updated_at) VALUES (%d, %q, %q)",
-			versionTable, v.Version, now, now)
-		if _, err := m.db.ExecContext(ctx, sqlstr); err != nil {
+
+		includes, err := m.includes(target)
+		if err != nil {
 			return m.check(err)
 		}
-		if err := m.run(ctx, v.up.Migrations...); err != nil {
-			return err
+
+		for i := range m.versions {
+			v := &m.versions[i]
+			if v.applied || !includes(v.Version) {
+				continue
+			}
+			if err := m.up(ctx, v); err != nil {
+				return err
+			}
 		}
-	}
-	return nil
+
+		if target == math.MaxInt {
+			return m.migrateRepeatables(ctx)
+		}
+		return nil
+	})
 }
 
 // Rollback migration 1 step.
 func (m *Migration) Rollback(ctx context.Context) error {
+	return m.locked(ctx, func() error {
+		if err := m.sync(ctx); err != nil {
+			return err
+		}
+
+		for i := range m.versions {
+			v := &m.versions[len(m.versions)-i-1]
+			if !v.applied {
+				continue
+			}
+			// only rollback one version.
+			return m.down(ctx, v)
+		}
+		return nil
+	})
+}
+
+// RollbackTo rolls back all applied versions newer than the target version.
+// In graph mode every applied version that is not the target or one of its ancestors is rolled back.
+func (m *Migration) RollbackTo(ctx context.Context, target int) error {
+	return m.locked(ctx, func() error {
+		if err := m.sync(ctx); err != nil {
+			return err
+		}
+
+		includes, err := m.includes(target)
+		if err != nil {
+			return m.check(err)
+		}
+
+		for i := range m.versions {
+			v := &m.versions[len(m.versions)-i-1]
+			if !v.applied || includes(v.Version) {
+				continue
+			}
+			if err := m.down(ctx, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status of all registered versions.
+func (m *Migration) Status(ctx context.Context) ([]VersionStatus, error) {
 	if err := m.sync(ctx); err != nil {
-		return err
+		return nil, err
+	}
+
+	result := make([]VersionStatus, len(m.versions))
+	for i, v := range m.versions {
+		result[i] = VersionStatus{
+			Version:   v.Version,
+			Applied:   v.applied,
+			AppliedAt: v.CreatedAt,
+			Up:        v.up.String(),
+			Down:      v.down.String(),
+		}
+	}
+	return result, nil
+}
+
+// Plan returns the steps needed to bring the database to the target version,
+// rollbacks of newer applied versions come first, followed by pending versions in order
+// and changed repeatable migrations when targeting the latest version.
+func (m *Migration) Plan(ctx context.Context, target int) ([]Step, error) {
+	if err := m.sync(ctx); err != nil {
+		return nil, err
 	}
 
+	includes, err := m.includes(target)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []Step
 	for i := range m.versions {
 		v := m.versions[len(m.versions)-i-1]
-		if !v.applied {
-			continue
+		if v.applied && !includes(v.Version) {
+			steps = append(steps, Step{Version: v.Version, Rollback: true, Description: v.down.String()})
+		}
+	}
+	for _, v := range m.versions {
+		if !v.applied && includes(v.Version) {
+			steps = append(steps, Step{Version: v.Version, Description: v.up.String()})
+		}
+	}
+
+	if target == math.MaxInt {
+		pending, err := m.pendingRepeatables(ctx)
+		if err != nil {
+			return nil, err
 		}
-		sqlstr := fmt.Sprintf("DELETE FROM %s WHERE version=%d", versionTable, v.Version)
-		if _, err := m.db.ExecContext(ctx, sqlstr); err != nil {
+		for _, r := range pending {
+			steps = append(steps, Step{Name: r.Name, Description: r.up.String()})
+		}
+	}
+	return steps, nil
+}
+
+func (m *Migration) up(ctx context.Context, v *version) error {
+	if err := m.validate(v.up.Migrations); err != nil {
+		return err
+	}
+
+	now := time.Now().Truncate(time.Microsecond).Format(timeLayout)
+	sqlstr := fmt.Sprintf("INSERT INTO %s(version, created_at, updated_at) VALUES (%d, %q, %q)",
+		m.versionTable(), v.Version, now, now)
+	if err := m.apply(ctx, v.up, sqlstr); err != nil {
+		return err
+	}
+	v.applied = true
+	return nil
+}
+
+func (m *Migration) down(ctx context.Context, v *version) error {
+	if err := m.validate(v.down.Migrations); err != nil {
+		return err
+	}
+
+	sqlstr := fmt.Sprintf("DELETE FROM %s WHERE version=%d", m.versionTable(), v.Version)
+	if err := m.apply(ctx, v.down, sqlstr); err != nil {
+		return err
+	}
+	v.applied = false
+	return nil
+}
+
+// apply migrations of schema along with the query recording the version,
+// in a transaction when enabled and after the migrations when the schema can't run in a transaction.
+func (m *Migration) apply(ctx context.Context, schema Schema, record string) error {
+	if !schema.transactional(m.adapter) {
+		if err := m.run(ctx, m.db, schema.Migrations...); err != nil {
+			return err
+		}
+		_, err := m.db.ExecContext(ctx, record)
+		return m.check(err)
+	}
+
+	var (
+		db             = m.db
+		tx             *sql.Tx
+		transactor, ok = m.db.(Transactor)
+	)
+
+	if m.transaction && ok {
+		var err error
+		if tx, err = transactor.BeginTx(ctx, nil); err != nil {
 			return m.check(err)
 		}
-		// only rollback one version.
-		return m.run(ctx, v.down.Migrations...)
+		defer tx.Rollback()
+		db = tx
+	}
+
+	if _, err := db.ExecContext(ctx, record); err != nil {
+		return m.check(err)
+	}
+	if err := m.run(ctx, db, schema.Migrations...); err != nil {
+		return err
+	}
+
+	if tx != nil {
+		return m.check(tx.Commit())
 	}
 	return nil
 }
 
-func (m *Migration) run(ctx context.Context, migrations ...Migratable) error {
+// locked runs fn while holding the locker, which is released even when fn panics on error of MustMigrate.
+// An unlock error is only returned when fn succeeds, it doesn't replace the error or panic of fn.
+func (m *Migration) locked(ctx context.Context, fn func() error) (err error) {
+	if m.locker == nil {
+		return fn()
+	}
+
+	if err := m.locker.Lock(ctx); err != nil {
+		return m.check(err)
+	}
+
+	completed := false
+	defer func() {
+		if unlockErr := m.locker.Unlock(ctx); completed && err == nil {
+			err = m.check(unlockErr)
+		}
+	}()
+
+	err = fn()
+	completed = true
+	return err
+}
+
+// validate migrations before anything is executed, so invalid versions are not recorded as applied.
+func (m *Migration) validate(migrations []Migratable) error {
+	if validator, ok := m.adapter.(Validator); ok {
+		for _, migration := range migrations {
+			if err := validator.Validate(migration); err != nil {
+				return m.check(err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migration) run(ctx context.Context, db Database, migrations ...Migratable) error {
 	for _, migration := range migrations {
 		if fn, ok := migration.(Do); ok {
-			if err := fn(ctx, m.db); err != nil {
+			if err := fn(ctx, db); err != nil {
 				return m.check(err)
 			}
-		} else {
-			if _, err := m.db.ExecContext(ctx, m.adapter.Build(migration)); err != nil {
-				if v, ok := m.adapter.(interface{ WrapError(error) error }); ok {
-					err = v.WrapError(err)
-				}
-				return m.check(err)
+			continue
+		}
+
+		if executor, ok := m.adapter.(Executor); ok {
+			handled, err := executor.Exec(ctx, db, migration)
+			if err != nil {
+				return m.check(m.wrapError(err))
+			}
+			if handled {
+				continue
 			}
 		}
+
+		if _, err := db.ExecContext(ctx, m.adapter.Build(migration)); err != nil {
+			return m.check(m.wrapError(err))
+		}
 	}
 	return nil
 }
 
+func (m *Migration) wrapError(err error) error {
+	if v, ok := m.adapter.(interface{ WrapError(error) error }); ok {
+		return v.WrapError(err)
+	}
+	return err
+}
+
 func (m *Migration) check(err error) error {
 	if m.panicOnError && err != nil {
 		panic(err)
@@ -0,0 +1,332 @@
+package dbm
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigration_Namespace(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		db      = newTestDatabase()
+		billing = New(testAdapter{}, db)
+		auth    = New(testAdapter{}, db)
+	)
+
+	billing.UseNamespace("billing")
+	billing.Register(1,
+		func(schema *Schema) { schema.Exec("CREATE TABLE invoices") },
+		func(schema *Schema) { schema.Exec("DROP TABLE invoices") },
+	)
+
+	auth.UseNamespace("auth")
+	auth.Register(2,
+		func(schema *Schema) { schema.Exec("CREATE TABLE users") },
+		func(schema *Schema) { schema.Exec("DROP TABLE users") },
+	)
+
+	assert.Nil(t, billing.Migrate(ctx))
+	assert.Nil(t, auth.Migrate(ctx))
+	assert.Nil(t, billing.Migrate(ctx))
+	assert.Nil(t, auth.Rollback(ctx))
+	assert.Nil(t, billing.Migrate(ctx))
+
+	assert.Equal(t, []string{"CREATE TABLE invoices", "CREATE TABLE users", "DROP TABLE users"}, db.Executed())
+	assert.Len(t, db.versions["dbm_schema_versions_billing"], 1)
+	assert.Len(t, db.versions["dbm_schema_versions_auth"], 0)
+	assert.Len(t, db.versions["dbm_schema_versions"], 0)
+}
+
+type testValidatingAdapter struct {
+	testAdapter
+}
+
+func (testValidatingAdapter) Validate(migration interface{}) error {
+	if migration == Raw("invalid") {
+		return ValidationError{Table: "users", Name: "age", Message: "invalid"}
+	}
+	return nil
+}
+
+type testLocker struct {
+	locked    bool
+	unlockErr error
+}
+
+func (l *testLocker) Lock(ctx context.Context) error {
+	l.locked = true
+	return nil
+}
+
+func (l *testLocker) Unlock(ctx context.Context) error {
+	l.locked = false
+	return l.unlockErr
+}
+
+func TestMigration_Locker(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		db     = newTestDatabase()
+		locker = &testLocker{}
+		m      = New(testAdapter{}, db)
+	)
+
+	db.failOn = "users"
+	m.UseLocker(locker)
+	m.Register(1,
+		func(schema *Schema) { schema.Exec("CREATE TABLE users") },
+		func(schema *Schema) { schema.Exec("DROP TABLE users") },
+	)
+
+	assert.PanicsWithError(t, "exec failed: CREATE TABLE users", func() { m.MustMigrate(ctx) })
+	assert.False(t, locker.locked)
+
+	t.Run("unlock error", func(t *testing.T) {
+		var m = New(testAdapter{}, newTestDatabase())
+
+		locker.unlockErr = errors.New("unlock failed")
+		m.UseLocker(locker)
+
+		assert.EqualError(t, m.Migrate(ctx), "unlock failed")
+		assert.False(t, locker.locked)
+	})
+}
+
+func TestMigration_Validate(t *testing.T) {
+	var (
+		ctx = context.Background()
+		db  = newTestDatabase()
+		m   = New(testValidatingAdapter{}, db)
+	)
+
+	m.Register(1,
+		func(schema *Schema) { schema.Exec("CREATE TABLE users") },
+		func(schema *Schema) { schema.Exec("DROP TABLE users") },
+	)
+	m.Register(2,
+		func(schema *Schema) {
+			schema.Exec("ALTER TABLE users ADD age INT")
+			schema.Exec("invalid")
+		},
+		func(schema *Schema) { schema.Exec("ALTER TABLE users DROP age") },
+	)
+
+	assert.Equal(t, ValidationError{Table: "users", Name: "age", Message: "invalid"}, m.Migrate(ctx))
+	assert.Equal(t, []string{"CREATE TABLE users"}, db.Executed())
+	assert.Len(t, db.versions["dbm_schema_versions"], 1)
+}
+
+func TestMigration_InvalidNamespace(t *testing.T) {
+	var m Migration
+
+	assert.PanicsWithValue(t, "dbm: invalid namespace `billing; DROP TABLE users`", func() {
+		m.UseNamespace("billing; DROP TABLE users")
+	})
+}
+
+func registerTestGraph(m *Migration) {
+	exec := func(sql string) func(schema *Schema) {
+		return func(schema *Schema) { schema.Exec(Raw(sql)) }
+	}
+
+	m.RegisterDependent(1, nil, exec("up 1"), exec("down 1"))
+	m.RegisterDependent(3, []int{1}, exec("up 3"), exec("down 3"))
+	m.RegisterDependent(2, []int{1}, exec("up 2"), exec("down 2"))
+	m.RegisterDependent(4, []int{3}, exec("up 4"), exec("down 4"))
+}
+
+func TestMigration_Graph(t *testing.T) {
+	var (
+		ctx = context.Background()
+		db  = newTestDatabase()
+		m   = New(testAdapter{}, db)
+	)
+
+	registerTestGraph(&m)
+
+	assert.EqualError(t, m.Migrate(ctx), "dbm: multiple heads: 2, 4")
+	assert.Nil(t, m.MigrateTo(ctx, 4))
+	assert.Equal(t, []string{"up 1", "up 3", "up 4"}, db.Executed())
+
+	m.Merge(5, []int{2, 4})
+
+	steps, err := m.Plan(ctx, math.MaxInt)
+	assert.Nil(t, err)
+	assert.Equal(t, []Step{{Version: 2, Description: "execute raw command"}, {Version: 5, Description: ""}}, steps)
+
+	assert.Nil(t, m.Migrate(ctx))
+	assert.Nil(t, m.Rollback(ctx))
+	assert.Nil(t, m.RollbackTo(ctx, 3))
+	assert.Equal(t, []string{"up 1", "up 3", "up 4", "up 2", "down 4", "down 2"}, db.Executed())
+
+	assert.Nil(t, m.RollbackTo(ctx, 0))
+	assert.Equal(t, []string{"up 1", "up 3", "up 4", "up 2", "down 4", "down 2", "down 3", "down 1"}, db.Executed())
+	assert.EqualError(t, m.MigrateTo(ctx, 9), "dbm: unknown migration: 9")
+}
+
+func TestMigration_GraphInvalid(t *testing.T) {
+	var (
+		ctx = context.Background()
+		m   = New(testAdapter{}, newTestDatabase())
+	)
+
+	m.RegisterDependent(1, []int{3}, func(schema *Schema) {}, func(schema *Schema) {})
+	m.RegisterDependent(2, []int{1}, func(schema *Schema) {}, func(schema *Schema) {})
+	m.RegisterDependent(3, []int{2}, func(schema *Schema) {}, func(schema *Schema) {})
+	assert.EqualError(t, m.Migrate(ctx), "dbm: migration dependency cycle: 1, 2, 3")
+
+	m = New(testAdapter{}, newTestDatabase())
+	m.RegisterDependent(1, []int{7}, func(schema *Schema) {}, func(schema *Schema) {})
+	assert.EqualError(t, m.Migrate(ctx), "dbm: migration 1 depends on unknown migration: 7")
+}
+
+func TestMigration_Repeatable(t *testing.T) {
+	var (
+		ctx = context.Background()
+		db  = newTestDatabase()
+		m   = New(testAdapter{}, db)
+	)
+
+	m.Register(1,
+		func(schema *Schema) { schema.Exec("CREATE TABLE users") },
+		func(schema *Schema) { schema.Exec("DROP TABLE users") },
+	)
+	m.RegisterRepeatable("view_active_users", func(schema *Schema) {
+		schema.Exec("CREATE OR REPLACE VIEW active_users AS SELECT * FROM users WHERE active")
+	})
+	m.RegisterRepeatable("function_now", func(schema *Schema) {
+		schema.Exec("CREATE OR REPLACE FUNCTION now_utc() ...")
+	})
+
+	steps, err := m.Plan(ctx, math.MaxInt)
+	assert.Nil(t, err)
+	assert.Equal(t, []Step{
+		{Version: 1, Description: "execute raw command"},
+		{Name: "function_now", Description: "execute raw command"},
+		{Name: "view_active_users", Description: "execute raw command"},
+	}, steps)
+
+	assert.Nil(t, m.Migrate(ctx))
+	assert.Nil(t, m.Migrate(ctx))
+	assert.Equal(t, []string{
+		"CREATE TABLE users",
+		"CREATE OR REPLACE FUNCTION now_utc() ...",
+		"CREATE OR REPLACE VIEW active_users AS SELECT * FROM users WHERE active",
+	}, db.Executed())
+
+	m.repeatables[1].up = Schema{}
+	m.repeatables[1].up.Exec("CREATE OR REPLACE VIEW active_users AS SELECT id FROM users WHERE active")
+
+	assert.Nil(t, m.Migrate(ctx))
+	assert.Nil(t, m.Migrate(ctx))
+	assert.Equal(t, "CREATE OR REPLACE VIEW active_users AS SELECT id FROM users WHERE active", db.Executed()[3])
+	assert.Len(t, db.Executed(), 4)
+	assert.Len(t, db.repeatables, 2)
+}
+
+func TestMigration_Transaction(t *testing.T) {
+	var (
+		ctx = context.Background()
+		db  = newTestDatabase()
+		m   = New(testAdapter{}, db)
+	)
+
+	m.UseTransaction(true)
+	m.Register(1,
+		func(schema *Schema) { schema.Exec("CREATE TABLE users") },
+		func(schema *Schema) { schema.Exec("DROP TABLE users") },
+	)
+	m.Register(2,
+		func(schema *Schema) {
+			schema.NoTransaction()
+			schema.Exec("CREATE INDEX CONCURRENTLY users_name")
+		},
+		func(schema *Schema) { schema.Exec("DROP INDEX users_name") },
+	)
+
+	assert.Nil(t, m.Migrate(ctx))
+	assert.Nil(t, m.Rollback(ctx))
+	assert.Equal(t, []string{
+		"BEGIN", "CREATE TABLE users", "COMMIT",
+		"CREATE INDEX CONCURRENTLY users_name",
+		"BEGIN", "DROP INDEX users_name", "COMMIT",
+	}, db.Executed())
+	assert.Len(t, db.versions["dbm_schema_versions"], 1)
+}
+
+type testFullTextAdapter struct {
+	testAdapter
+}
+
+func (testFullTextAdapter) Transactional(migration interface{}) bool {
+	index, ok := migration.(Index)
+	return !ok || !index.FullText
+}
+
+func TestMigration_Transaction_concurrently(t *testing.T) {
+	var (
+		ctx = context.Background()
+		db  = newTestDatabase()
+		m   = New(testAdapter{}, db)
+	)
+
+	m.UseTransaction(true)
+	m.Register(1,
+		func(schema *Schema) { schema.CreateIndex("users", "users_name", []string{"name"}, Concurrently(true)) },
+		func(schema *Schema) { schema.DropIndex("users", "users_name") },
+	)
+	m.Register(2,
+		func(schema *Schema) { schema.CreateFullTextIndex("posts", "posts_search", []string{"body"}) },
+		func(schema *Schema) { schema.DropFullTextIndex("posts", "posts_search") },
+	)
+
+	assert.Nil(t, m.Migrate(ctx))
+	assert.Nil(t, m.Rollback(ctx))
+	assert.Equal(t, []string{
+		"create index users_name on users",
+		"BEGIN", "create index posts_search on posts", "COMMIT",
+		"BEGIN", "drop index posts_search on posts", "COMMIT",
+	}, db.Executed())
+
+	t.Run("adapter", func(t *testing.T) {
+		var (
+			db = newTestDatabase()
+			m  = New(testFullTextAdapter{}, db)
+		)
+
+		m.UseTransaction(true)
+		m.Register(1,
+			func(schema *Schema) { schema.CreateFullTextIndex("posts", "posts_search", []string{"body"}) },
+			func(schema *Schema) { schema.DropFullTextIndex("posts", "posts_search") },
+		)
+
+		assert.Nil(t, m.Migrate(ctx))
+		assert.Nil(t, m.Rollback(ctx))
+		assert.Equal(t, []string{"create index posts_search on posts", "drop index posts_search on posts"}, db.Executed())
+	})
+}
+
+func TestMigration_NoTransaction_failed(t *testing.T) {
+	var (
+		ctx = context.Background()
+		db  = newTestDatabase()
+		m   = New(testAdapter{}, db)
+	)
+
+	db.failOn = "CONCURRENTLY"
+	m.UseTransaction(true)
+	m.Register(1,
+		func(schema *Schema) {
+			schema.NoTransaction()
+			schema.Exec("CREATE INDEX CONCURRENTLY users_name")
+		},
+		func(schema *Schema) { schema.Exec("DROP INDEX users_name") },
+	)
+
+	assert.EqualError(t, m.Migrate(ctx), "exec failed: CREATE INDEX CONCURRENTLY users_name")
+	assert.Empty(t, db.versions["dbm_schema_versions"])
+}
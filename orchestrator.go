@@ -0,0 +1,155 @@
+package dbm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrCanaryFailed is returned for targets skipped because a canary target failed.
+var ErrCanaryFailed = errors.New("dbm: canary migration failed")
+
+// Target is a database migrated by Orchestrator.
+type Target struct {
+	Name    string
+	Adapter Adapter
+	DB      Database
+}
+
+// TargetResult is the outcome of migrating a single target.
+type TargetResult struct {
+	Target  Target
+	Skipped bool
+	Err     error
+}
+
+// Orchestrator runs the same registered migrations against many databases, e.g. one schema per tenant.
+type Orchestrator struct {
+	// Parallelism is the maximum number of targets migrated at the same time, defaults to 1.
+	Parallelism int
+	// Canary is the number of leading targets migrated first,
+	// the remaining targets are only migrated when all canaries succeed.
+	Canary int
+	// Namespace of the version history on every target, see Migration.UseNamespace.
+	Namespace string
+	// Transaction runs every version in a transaction, see Migration.UseTransaction.
+	Transaction bool
+
+	versions    versions
+	repeatables []repeatable
+}
+
+// Register a migration for every target.
+func (o *Orchestrator) Register(v int, up func(schema *Schema), down func(schema *Schema)) {
+	var m Migration
+	m.Register(v, up, down)
+	o.versions = append(o.versions, m.versions...)
+}
+
+// RegisterRepeatable registers a repeatable migration for every target, see Migration.RegisterRepeatable.
+func (o *Orchestrator) RegisterRepeatable(name string, up func(schema *Schema)) {
+	var m Migration
+	m.RegisterRepeatable(name, up)
+	o.repeatables = append(o.repeatables, m.repeatables...)
+}
+
+// Migrate every target to the latest schema version.
+// Failures do not stop other targets, results are returned in the order of targets
+// along with a joined error of every failed target.
+// Every target is skipped when the namespace is not valid.
+func (o *Orchestrator) Migrate(ctx context.Context, targets []Target) ([]TargetResult, error) {
+	var (
+		results = make([]TargetResult, len(targets))
+		canary  = o.Canary
+	)
+
+	for i := range targets {
+		results[i].Target = targets[i]
+	}
+
+	// the namespace is checked once, UseNamespace would panic inside the workers.
+	if err := validateNamespace(o.Namespace); err != nil {
+		for i := range results {
+			results[i].Skipped = true
+			results[i].Err = err
+		}
+		return results, err
+	}
+
+	if canary > len(targets) {
+		canary = len(targets)
+	}
+
+	if canary > 0 {
+		o.migrate(ctx, results[:canary])
+
+		for _, result := range results[:canary] {
+			if result.Err != nil {
+				for i := canary; i < len(results); i++ {
+					results[i].Skipped = true
+					results[i].Err = ErrCanaryFailed
+				}
+				return results, o.errors(results)
+			}
+		}
+	}
+
+	o.migrate(ctx, results[canary:])
+	return results, o.errors(results)
+}
+
+func (o *Orchestrator) migrate(ctx context.Context, results []TargetResult) {
+	var (
+		wg          sync.WaitGroup
+		parallelism = o.Parallelism
+	)
+
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	sem := make(chan struct{}, parallelism)
+
+	for i := range results {
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			results[i].Skipped = true
+			results[i].Err = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *TargetResult) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			m := New(result.Target.Adapter, result.Target.DB)
+			m.UseNamespace(o.Namespace)
+			m.UseTransaction(o.Transaction)
+			m.versions = append(versions(nil), o.versions...)
+			m.repeatables = append([]repeatable(nil), o.repeatables...)
+			result.Err = m.Migrate(ctx)
+		}(&results[i])
+	}
+
+	wg.Wait()
+}
+
+func (o *Orchestrator) errors(results []TargetResult) error {
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil && result.Err != ErrCanaryFailed {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Target.Name, result.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
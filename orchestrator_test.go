@@ -0,0 +1,111 @@
+package dbm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOrchestrator(parallelism int, canary int) *Orchestrator {
+	o := &Orchestrator{Parallelism: parallelism, Canary: canary}
+	o.Register(2,
+		func(schema *Schema) { schema.Exec("CREATE TABLE todos") },
+		func(schema *Schema) { schema.Exec("DROP TABLE todos") },
+	)
+	o.Register(1,
+		func(schema *Schema) { schema.Exec("CREATE TABLE users") },
+		func(schema *Schema) { schema.Exec("DROP TABLE users") },
+	)
+	return o
+}
+
+func TestOrchestrator_Migrate(t *testing.T) {
+	var (
+		o       = newTestOrchestrator(2, 0)
+		dbs     = []*testDatabase{newTestDatabase(), newTestDatabase(), newTestDatabase()}
+		targets = []Target{
+			{Name: "tenant_1", Adapter: testAdapter{}, DB: dbs[0]},
+			{Name: "tenant_2", Adapter: testAdapter{}, DB: dbs[1]},
+			{Name: "tenant_3", Adapter: testAdapter{}, DB: dbs[2]},
+		}
+	)
+
+	dbs[1].failOn = "todos"
+
+	results, err := o.Migrate(context.Background(), targets)
+	assert.EqualError(t, err, "tenant_2: exec failed: CREATE TABLE todos")
+	assert.Len(t, results, 3)
+
+	for i, result := range results {
+		assert.Equal(t, targets[i], result.Target)
+		assert.False(t, result.Skipped)
+	}
+
+	assert.Nil(t, results[0].Err)
+	assert.NotNil(t, results[1].Err)
+	assert.Nil(t, results[2].Err)
+	assert.Equal(t, []string{"CREATE TABLE users", "CREATE TABLE todos"}, dbs[0].Executed())
+	assert.Equal(t, []string{"CREATE TABLE users"}, dbs[1].Executed())
+	assert.Equal(t, []string{"CREATE TABLE users", "CREATE TABLE todos"}, dbs[2].Executed())
+}
+
+func TestOrchestrator_Canary(t *testing.T) {
+	var (
+		o       = newTestOrchestrator(4, 1)
+		dbs     = []*testDatabase{newTestDatabase(), newTestDatabase()}
+		targets = []Target{
+			{Name: "canary", Adapter: testAdapter{}, DB: dbs[0]},
+			{Name: "tenant", Adapter: testAdapter{}, DB: dbs[1]},
+		}
+	)
+
+	results, err := o.Migrate(context.Background(), targets)
+	assert.Nil(t, err)
+	assert.Nil(t, results[1].Err)
+	assert.Len(t, dbs[1].Executed(), 2)
+
+	dbs = []*testDatabase{newTestDatabase(), newTestDatabase()}
+	dbs[0].failOn = "users"
+	targets[0].DB = dbs[0]
+	targets[1].DB = dbs[1]
+
+	results, err = o.Migrate(context.Background(), targets)
+	assert.EqualError(t, err, "canary: exec failed: CREATE TABLE users")
+	assert.False(t, results[0].Skipped)
+	assert.True(t, results[1].Skipped)
+	assert.True(t, errors.Is(results[1].Err, ErrCanaryFailed))
+	assert.Empty(t, dbs[1].Executed())
+}
+
+func TestOrchestrator_Canceled(t *testing.T) {
+	var (
+		o           = newTestOrchestrator(1, 0)
+		db          = newTestDatabase()
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	cancel()
+
+	results, err := o.Migrate(ctx, []Target{{Name: "tenant", Adapter: testAdapter{}, DB: db}})
+	assert.EqualError(t, err, "tenant: context canceled")
+	assert.True(t, results[0].Skipped)
+	assert.Equal(t, context.Canceled, results[0].Err)
+	assert.Empty(t, db.Executed())
+}
+
+func TestOrchestrator_InvalidNamespace(t *testing.T) {
+	var (
+		o  = newTestOrchestrator(2, 0)
+		db = newTestDatabase()
+	)
+
+	o.Namespace = "tenant-1"
+
+	results, err := o.Migrate(context.Background(), []Target{{Name: "tenant", Adapter: testAdapter{}, DB: db}})
+	assert.EqualError(t, err, "dbm: invalid namespace `tenant-1`")
+	assert.True(t, results[0].Skipped)
+	assert.Equal(t, err, results[0].Err)
+	assert.Empty(t, db.Executed())
+}
@@ -0,0 +1,161 @@
+package dbm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const repeatableTable = "dbm_repeatable_migrations"
+
+type repeatable struct {
+	Name     string
+	Checksum string
+
+	up Schema
+}
+
+// RegisterRepeatable registers a migration by name that is re-applied whenever its rendered SQL changes,
+// useful for views, functions and triggers that are easier to maintain as a single definition.
+// Repeatable migrations run in name order after all versioned migrations have been applied,
+// Do migrations are not part of the checksum, so changes in go code alone won't trigger a re-run.
+func (m *Migration) RegisterRepeatable(name string, up func(schema *Schema)) {
+	var upSchema Schema
+
+	up(&upSchema)
+
+	m.repeatables = append(m.repeatables, repeatable{Name: name, up: upSchema})
+}
+
+func (m Migration) repeatableTable() string {
+	if m.namespace == "" {
+		return repeatableTable
+	}
+	return repeatableTable + "_" + m.namespace
+}
+
+func (m Migration) buildRepeatableTableDefinition() Table {
+	var schema Schema
+	schema.CreateTableIfNotExists(m.repeatableTable(), func(t *Table) {
+		t.ID("id")
+		t.String("name", Unique(true))
+		t.String("checksum", Limit(64))
+		t.DateTime("created_at")
+		t.DateTime("updated_at")
+	})
+
+	return schema.Migrations[0].(Table)
+}
+
+func (m *Migration) checksum(r repeatable) string {
+	var (
+		hash       = sha256.New()
+		statements = make([]string, len(r.up.Migrations))
+	)
+
+	for i, migration := range r.up.Migrations {
+		if _, ok := migration.(Do); ok {
+			statements[i] = migration.description()
+		} else {
+			statements[i] = m.adapter.Build(migration)
+		}
+	}
+
+	hash.Write([]byte(strings.Join(statements, "\n")))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (m *Migration) syncRepeatables(ctx context.Context) error {
+	if !m.repeatableTableExists {
+		if err := m.run(ctx, m.db, m.buildRepeatableTableDefinition()); err != nil {
+			return err
+		}
+		m.repeatableTableExists = true
+	}
+
+	sqlstr := "SELECT name, checksum FROM " + m.repeatableTable()
+	rows, err := m.db.QueryContext(ctx, sqlstr)
+	if err != nil {
+		return m.check(err)
+	}
+	defer rows.Close()
+
+	checksums := map[string]string{}
+	for rows.Next() {
+		var name, checksum string
+		if err = rows.Scan(&name, &checksum); err != nil {
+			return m.check(fmt.Errorf("sync repeatable row scan: %w", err))
+		}
+		checksums[name] = checksum
+	}
+
+	sort.Slice(m.repeatables, func(i, j int) bool {
+		return m.repeatables[i].Name < m.repeatables[j].Name
+	})
+
+	for i := range m.repeatables {
+		m.repeatables[i].Checksum = checksums[m.repeatables[i].Name]
+	}
+	return nil
+}
+
+// pendingRepeatables returns repeatable migrations whose checksum differs from the stored one.
+func (m *Migration) pendingRepeatables(ctx context.Context) ([]repeatable, error) {
+	if len(m.repeatables) == 0 {
+		return nil, nil
+	}
+
+	if err := m.syncRepeatables(ctx); err != nil {
+		return nil, err
+	}
+
+	var pending []repeatable
+	for _, r := range m.repeatables {
+		if r.Checksum != m.checksum(r) {
+			pending = append(pending, r)
+		}
+	}
+	return pending, nil
+}
+
+func (m *Migration) migrateRepeatables(ctx context.Context) error {
+	pending, err := m.pendingRepeatables(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range pending {
+		if err := m.validate(r.up.Migrations); err != nil {
+			return err
+		}
+
+		if err := m.run(ctx, m.db, r.up.Migrations...); err != nil {
+			return err
+		}
+
+		var (
+			checksum = m.checksum(r)
+			now      = time.Now().Truncate(time.Microsecond).Format(timeLayout)
+			name     = "'" + strings.ReplaceAll(r.Name, "'", "''") + "'"
+			sqlstr   string
+		)
+
+		if r.Checksum != "" {
+			sqlstr = fmt.Sprintf("UPDATE %s SET checksum='%s', updated_at=%q WHERE name=%s",
+				m.repeatableTable(), checksum, now, name)
+		} else {
+			sqlstr = fmt.Sprintf("INSERT INTO %s(name, checksum, created_at, updated_at) VALUES (%s, '%s', %q, %q)",
+				m.repeatableTable(), name, checksum, now, now)
+		}
+
+		if _, err := m.db.ExecContext(ctx, sqlstr); err != nil {
+			return m.check(err)
+		}
+	}
+
+	return nil
+}
@@ -32,6 +32,8 @@ type Migratable interface {
 // Schema builder.
 type Schema struct {
 	Migrations []Migratable
+
+	noTransaction bool
 }
 
 func (s *Schema) add(migration Migratable) {
@@ -95,6 +97,50 @@ func (s *Schema) DropColumn(table string, name string, options ...ColumnOption)
 	s.add(at.Table)
 }
 
+// ChangeColumn redefines type, nullability and default of an existing column.
+// Values of enum columns can only be added on PostgreSQL, where the version runs outside a transaction.
+// MySQL replaces the whole column definition, options must include everything the column keeps, such as its limit, default and comment.
+func (s *Schema) ChangeColumn(table string, name string, typ ColumnType, options ...ColumnOption) {
+	at := alterTable(table, nil)
+	at.ChangeColumn(name, typ, options...)
+	s.add(at.Table)
+}
+
+// ResetIdentity so the next value generated by the identity column of table is value.
+func (s *Schema) ResetIdentity(table string, value int) {
+	at := alterTable(table, nil)
+	at.ResetIdentity(value)
+	s.add(at.Table)
+}
+
+// DropForeignKey by name.
+func (s *Schema) DropForeignKey(table string, name string, options ...KeyOption) {
+	at := alterTable(table, nil)
+	at.DropForeignKey(name, options...)
+	s.add(at.Table)
+}
+
+// DropUnique key by name.
+func (s *Schema) DropUnique(table string, name string, options ...KeyOption) {
+	at := alterTable(table, nil)
+	at.DropUnique(name, options...)
+	s.add(at.Table)
+}
+
+// DropPrimaryKey of a table.
+func (s *Schema) DropPrimaryKey(table string, options ...KeyOption) {
+	at := alterTable(table, nil)
+	at.DropPrimaryKey(options...)
+	s.add(at.Table)
+}
+
+// RenameConstraint of type to a new name.
+func (s *Schema) RenameConstraint(table string, name string, newName string, typ KeyType, options ...KeyOption) {
+	at := alterTable(table, nil)
+	at.RenameConstraint(name, newName, typ, options...)
+	s.add(at.Table)
+}
+
 // CreateIndex for columns on a table.
 func (s *Schema) CreateIndex(table string, name string, column []string, options ...IndexOption) {
 	s.add(createIndex(table, name, column, options))
@@ -110,6 +156,63 @@ func (s *Schema) DropIndex(table string, name string, options ...IndexOption) {
 	s.add(dropIndex(table, name, options))
 }
 
+// CreateFullTextIndex for text search over columns of a table.
+// It's a FULLTEXT index on MySQL, a GIN index over to_tsvector on PostgreSQL,
+// a full-text index in a catalog on MSSQL and an FTS5 table kept in sync by triggers on SQLite.
+// The version runs outside a transaction on MSSQL as it can't create full-text indexes in a transaction,
+// and SQLite requires the table to have a rowid.
+func (s *Schema) CreateFullTextIndex(table string, name string, columns []string, options ...IndexOption) {
+	s.add(createFullTextIndex(table, name, columns, options))
+}
+
+// DropFullTextIndex by name.
+func (s *Schema) DropFullTextIndex(table string, name string, options ...IndexOption) {
+	s.add(dropFullTextIndex(table, name, options))
+}
+
+// RenameIndex of a table to a new name.
+func (s *Schema) RenameIndex(table string, name string, newName string, options ...IndexOption) {
+	s.add(renameIndex(table, name, newName, options))
+}
+
+// AddEnumValue to an existing enum type, only supported by PostgreSQL.
+func (s *Schema) AddEnumValue(name string, value string) {
+	s.add(alterEnumType(name, value))
+}
+
+// DropType by name, only supported by PostgreSQL.
+func (s *Schema) DropType(name string) {
+	s.add(dropType(name))
+}
+
+// NoTransaction runs the version outside a transaction, it's implied by concurrent indexes
+// and by migrations the adapter can't run in a transaction, such as full-text indexes on MSSQL.
+// The version is only recorded once all of its migrations succeed, so a failed run is retried.
+func (s *Schema) NoTransaction() {
+	s.noTransaction = true
+}
+
+// transactional returns false when the schema opted out of transactions or has migrations that can't run in a transaction,
+// either concurrent indexes or migrations rejected by adapter implementing Transactional.
+func (s Schema) transactional(adapter Adapter) bool {
+	if s.noTransaction {
+		return false
+	}
+
+	checker, _ := adapter.(Transactional)
+	for _, migration := range s.Migrations {
+		if index, ok := migration.(Index); ok && index.Concurrently {
+			return false
+		}
+
+		if checker != nil && !checker.Transactional(migration) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Exec queries.
 func (s *Schema) Exec(raw Raw) {
 	s.add(raw)
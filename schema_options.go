@@ -1,7 +1,7 @@
 package dbm
 
 // TableOption interface.
-// Available options are: Comment, Options.
+// Available options are: Charset, Collation, Comment, Options.
 type TableOption interface {
 	applyTable(table *Table)
 }
@@ -13,7 +13,7 @@ func applyTableOptions(table *Table, options []TableOption) {
 }
 
 // ColumnOption interface.
-// Available options are: Nil, Unsigned, Limit, Precision, Scale, Default, Comment, Options.
+// Available options are: Nil, Unsigned, Limit, Precision, Scale, Values, Charset, Collation, Default, DefaultExpr, DefaultNow, DefaultUUID, AutoUpdate, Generated, Identity, WithTimezone, Using, Comment, Options.
 type ColumnOption interface {
 	applyColumn(column *Column)
 }
@@ -25,7 +25,7 @@ func applyColumnOptions(column *Column, options []ColumnOption) {
 }
 
 // KeyOption interface.
-// Available options are: Comment, Options.
+// Available options are: Name, OnDelete, OnUpdate, MatchFull, Deferrable, InitiallyDeferred, Options.
 type KeyOption interface {
 	applyKey(key *Key)
 }
@@ -56,13 +56,21 @@ func (r Unique) applyIndex(index *Index) {
 
 // Limit options.
 // When passed as query, it limits returned result from database.
-// When passed as column option, it sets the maximum size of the string/text/binary/integer columns.
+// When passed as column option, it sets the maximum size of the string/text/binary columns and the size in bytes of integer columns,
+// limits of integer columns above 8 are display widths such as INT(11) and are ignored.
 type Limit int
 
 func (l Limit) applyColumn(column *Column) {
 	column.Limit = int(l)
 }
 
+// Values allowed in an enum column.
+type Values []string
+
+func (v Values) applyColumn(column *Column) {
+	column.Values = []string(v)
+}
+
 // Required disallows nil values in the column.
 type Required bool
 
@@ -78,6 +86,8 @@ func (u Unsigned) applyColumn(column *Column) {
 }
 
 // Precision defines the precision for the decimal fields, representing the total number of digits in the number.
+// When passed to DateTime and Time columns, it defines the number of fractional seconds digits.
+// When passed to integer columns without Limit, the storage size is chosen to fit the number of digits.
 type Precision int
 
 func (p Precision) applyColumn(column *Column) {
@@ -104,20 +114,234 @@ func Default(def any) ColumnOption {
 	return defaultValue{value: def}
 }
 
-// OnDelete option for foreign key.
+// DefaultExpr sets the default of the column to an sql expression, which is written unquoted.
+func DefaultExpr(sql string) ColumnOption {
+	return defaultValue{value: Expr(sql)}
+}
+
+// DefaultNow sets the default of the column to the current date and time.
+func DefaultNow() ColumnOption {
+	return defaultValue{value: ExprNow}
+}
+
+// DefaultUUID sets the default of the column to a randomly generated UUID.
+func DefaultUUID() ColumnOption {
+	return defaultValue{value: ExprUUID}
+}
+
+// AutoUpdate sets the column to the current timestamp whenever the row is updated.
+// Only supported by MySQL on datetime columns, other adapters exclude it with a warning.
+type AutoUpdate bool
+
+func (au AutoUpdate) applyColumn(column *Column) {
+	column.AutoUpdate = bool(au)
+}
+
+type generated struct {
+	expr   string
+	stored bool
+}
+
+func (g generated) applyColumn(column *Column) {
+	column.Generated = g.expr
+	column.Stored = g.stored
+}
+
+// Generated computes the column from an sql expression, the value is stored when stored is true, otherwise it's computed when read.
+func Generated(expr string, stored bool) ColumnOption {
+	return generated{expr: expr, stored: stored}
+}
+
+// Identity generates values of integer column using a sequence that starts at start and is incremented by increment.
+// Explicit values are rejected when always is true.
+func Identity(always bool, start int, increment int) ColumnOption {
+	return identity{Always: always, Start: start, Increment: increment}
+}
+
+type identity ColumnIdentity
+
+func (i identity) applyColumn(column *Column) {
+	ci := ColumnIdentity(i)
+	column.Identity = &ci
+}
+
+// Using defines the expression used to convert existing values when changing the type of a column.
+// Only supported by PostgreSQL, defaults to casting the column to the new type.
+// When passed as index option, it sets the index method, such as gin.
+type Using string
+
+func (u Using) applyColumn(column *Column) {
+	column.Using = string(u)
+}
+
+func (u Using) applyIndex(index *Index) {
+	index.Using = string(u)
+}
+
+// Where sets the predicate of a partial index, only rows matching it are indexed.
+type Where string
+
+func (w Where) applyIndex(index *Index) {
+	index.Where = string(w)
+}
+
+// Concurrently builds or drops the index without blocking writes, the version runs outside a transaction as if Schema.NoTransaction was called.
+type Concurrently bool
+
+func (c Concurrently) applyIndex(index *Index) {
+	index.Concurrently = bool(c)
+}
+
+// Language of full-text index, it's the text search configuration on PostgreSQL and defaults to simple.
+type Language string
+
+func (l Language) applyIndex(index *Index) {
+	index.Language = string(l)
+}
+
+// Catalog of full-text index on MSSQL, it's created when it doesn't exist and defaults to dbm_fulltext.
+type Catalog string
+
+func (c Catalog) applyIndex(index *Index) {
+	index.Catalog = string(c)
+}
+
+// Include stores non-key columns in the index, so queries reading them can be answered from the index alone.
+func Include(columns ...string) IndexOption {
+	return include(columns)
+}
+
+type include []string
+
+func (i include) applyIndex(index *Index) {
+	index.Include = append(index.Include, i...)
+}
+
+// Expression adds sql expressions to the index columns, they are written as is instead of escaped.
+// Expressions already listed in the index columns keep their position.
+func Expression(expressions ...string) IndexOption {
+	return expression(expressions)
+}
+
+type expression []string
+
+func (e expression) applyIndex(index *Index) {
+	for _, expr := range e {
+		if !index.HasColumn(expr) {
+			index.Columns = append(index.Columns, expr)
+		}
+
+		index.Expressions = append(index.Expressions, expr)
+	}
+}
+
+// Asc sorts the index columns in ascending order.
+func Asc(columns ...string) IndexOption {
+	return order{columns: columns}
+}
+
+// Desc sorts the index columns in descending order.
+func Desc(columns ...string) IndexOption {
+	return order{columns: columns, desc: true}
+}
+
+type order struct {
+	columns []string
+	desc    bool
+}
+
+func (o order) applyIndex(index *Index) {
+	if o.desc {
+		index.Descending = append(index.Descending, o.columns...)
+	} else {
+		index.Ascending = append(index.Ascending, o.columns...)
+	}
+}
+
+// OnDelete option for foreign key, the action is one of CASCADE, SET NULL, SET DEFAULT, RESTRICT and NO ACTION.
 type OnDelete string
 
 func (od OnDelete) applyKey(key *Key) {
 	key.Reference.OnDelete = string(od)
 }
 
-// OnUpdate option for foreign key.
+// OnUpdate option for foreign key, the action is one of CASCADE, SET NULL, SET DEFAULT, RESTRICT and NO ACTION.
 type OnUpdate string
 
 func (ou OnUpdate) applyKey(key *Key) {
 	key.Reference.OnUpdate = string(ou)
 }
 
+// MatchFull option for composite foreign key, rows must either reference a row using all columns or have all columns null.
+// Only supported by PostgreSQL.
+type MatchFull bool
+
+func (mf MatchFull) applyKey(key *Key) {
+	key.Reference.MatchFull = bool(mf)
+}
+
+// Deferrable option for foreign key, allows the check to be deferred until the end of the transaction.
+// Only supported by PostgreSQL and SQLite.
+type Deferrable bool
+
+func (d Deferrable) applyKey(key *Key) {
+	key.Reference.Deferrable = bool(d)
+}
+
+// InitiallyDeferred option for foreign key, defers the check until the end of the transaction unless set immediate.
+// Only supported by PostgreSQL and SQLite.
+type InitiallyDeferred bool
+
+func (id InitiallyDeferred) applyKey(key *Key) {
+	key.Reference.InitiallyDeferred = bool(id)
+}
+
+// WithTimezone chooses between time zone aware and naive DateTime columns, DateTime columns are time zone aware by default.
+// It has no effect on MySQL and SQLite, which don't store time zones.
+type WithTimezone bool
+
+func (w WithTimezone) applyColumn(column *Column) {
+	column.WithoutTimezone = !bool(w)
+}
+
+// Charset of table and string columns, only supported by MySQL.
+type Charset string
+
+func (c Charset) applyTable(table *Table) {
+	table.Charset = string(c)
+}
+
+func (c Charset) applyColumn(column *Column) {
+	column.Charset = string(c)
+}
+
+// Collation of string columns, used to compare and sort their values.
+// When passed as table option, it sets the default collation of columns, only supported by MySQL.
+type Collation string
+
+func (c Collation) applyTable(table *Table) {
+	table.Collation = string(c)
+}
+
+func (c Collation) applyColumn(column *Column) {
+	column.Collation = string(c)
+}
+
+// Comment describes table, column and index in the database.
+type Comment string
+
+func (c Comment) applyTable(table *Table) {
+	table.Comment = string(c)
+}
+
+func (c Comment) applyColumn(column *Column) {
+	column.Comment = string(c)
+}
+
+func (c Comment) applyIndex(index *Index) {
+	index.Comment = string(c)
+}
+
 // Options options for table, column and index.
 type Options string
 
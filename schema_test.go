@@ -146,6 +146,73 @@ func TestSchema_DropColumn(t *testing.T) {
 	}, schema.Migrations[0])
 }
 
+func TestSchema_ChangeColumn(t *testing.T) {
+	var schema Schema
+
+	schema.ChangeColumn("users", "name", String, Limit(512))
+
+	assert.Equal(t, Table{
+		Op:   SchemaAlter,
+		Name: "users",
+		Definitions: []TableDefinition{
+			Column{Name: "name", Type: String, Limit: 512, Op: SchemaAlter},
+		},
+	}, schema.Migrations[0])
+}
+
+func TestSchema_DropKeys(t *testing.T) {
+	var schema Schema
+
+	schema.DropForeignKey("users", "fk")
+	schema.DropUnique("users", "uq")
+	schema.DropPrimaryKey("users", Name("users_pkey"))
+	schema.RenameConstraint("users", "uq", "uq_email", UniqueKey)
+
+	assert.Equal(t, []Migratable{
+		Table{Op: SchemaAlter, Name: "users", Definitions: []TableDefinition{Key{Op: SchemaDrop, Type: ForeignKey, Name: "fk"}}},
+		Table{Op: SchemaAlter, Name: "users", Definitions: []TableDefinition{Key{Op: SchemaDrop, Type: UniqueKey, Name: "uq"}}},
+		Table{Op: SchemaAlter, Name: "users", Definitions: []TableDefinition{Key{Op: SchemaDrop, Type: PrimaryKey, Name: "users_pkey"}}},
+		Table{Op: SchemaAlter, Name: "users", Definitions: []TableDefinition{Key{Op: SchemaRename, Name: "uq", Type: UniqueKey, Rename: "uq_email"}}},
+	}, schema.Migrations)
+}
+
+func TestSchema_ResetIdentity(t *testing.T) {
+	var schema Schema
+
+	schema.ResetIdentity("invoices", 1000)
+
+	assert.Equal(t, Table{
+		Op:          SchemaAlter,
+		Name:        "invoices",
+		Definitions: []TableDefinition{IdentityReset{Value: 1000}},
+	}, schema.Migrations[0])
+}
+
+func TestSchema_Enum(t *testing.T) {
+	var schema Schema
+
+	schema.CreateTable("orders", func(t *Table) {
+		t.Enum("status", []string{"pending", "paid"}, Default("pending"))
+	})
+	schema.AlterTable("orders", func(t *AlterTable) {
+		t.ChangeColumn("status", Enum, Values{"pending", "paid", "refunded"})
+	})
+	schema.AddEnumValue("orders_status", "shipped")
+	schema.DropType("orders_status")
+
+	assert.Equal(t, []Migratable{
+		Table{Op: SchemaCreate, Name: "orders", Definitions: []TableDefinition{
+			Column{Name: "status", Type: Enum, Values: []string{"pending", "paid"}, TypeName: "orders_status", Default: "pending"},
+		}},
+		Table{Op: SchemaAlter, Name: "orders", Definitions: []TableDefinition{
+			Column{Op: SchemaAlter, Name: "status", Type: Enum, Values: []string{"pending", "paid", "refunded"}, TypeName: "orders_status"},
+		}},
+		Type{Op: SchemaAlter, Name: "orders_status", Values: []string{"shipped"}},
+		Type{Op: SchemaDrop, Name: "orders_status"},
+	}, schema.Migrations)
+	assert.Equal(t, "create table orders, alter table orders, alter type orders_status, drop type orders_status", schema.String())
+}
+
 func TestSchema_CreateIndex(t *testing.T) {
 	var schema Schema
 
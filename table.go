@@ -5,6 +5,13 @@ type TableDefinition interface {
 	internalTableDefinition()
 }
 
+// IdentityReset definition, sets the next value generated by the identity column of table.
+type IdentityReset struct {
+	Value int
+}
+
+func (IdentityReset) internalTableDefinition() {}
+
 // Table definition.
 type Table struct {
 	Op          SchemaOp
@@ -12,15 +19,24 @@ type Table struct {
 	Rename      string
 	Definitions []TableDefinition
 	Optional    bool
+	Charset     string
+	Collation   string
+	Comment     string
 	Options     string
 }
 
 // Column defines a column with name and type.
 func (t *Table) Column(name string, typ ColumnType, options ...ColumnOption) {
-	if typ == BigID || typ == ID {
+	switch typ {
+	case BigID, ID:
 		options = append([]ColumnOption{Primary(true)}, options...)
+	case UUIDID:
+		options = append([]ColumnOption{Primary(true), DefaultUUID()}, options...)
 	}
-	t.Definitions = append(t.Definitions, createColumn(name, typ, options))
+
+	column := createColumn(name, typ, options)
+	column.TypeName = t.typeName(column)
+	t.Definitions = append(t.Definitions, column)
 }
 
 // ID defines a column with name and ID type.
@@ -35,6 +51,12 @@ func (t *Table) BigID(name string, options ...ColumnOption) {
 	t.Column(name, BigID, options...)
 }
 
+// UUIDID defines a column with name and UUID ID type.
+// the resulting database type will depends on database, it's generated using the UUID function of database.
+func (t *Table) UUIDID(name string, options ...ColumnOption) {
+	t.Column(name, UUIDID, options...)
+}
+
 // Bool defines a column with name and Bool type.
 func (t *Table) Bool(name string, options ...ColumnOption) {
 	t.Column(name, Bool, options...)
@@ -70,6 +92,11 @@ func (t *Table) String(name string, options ...ColumnOption) {
 	t.Column(name, String, options...)
 }
 
+// Char defines a column with name and fixed length Char type.
+func (t *Table) Char(name string, options ...ColumnOption) {
+	t.Column(name, Char, options...)
+}
+
 // Text defines a column with name and Text type.
 func (t *Table) Text(name string, options ...ColumnOption) {
 	t.Column(name, Text, options...)
@@ -95,6 +122,27 @@ func (t *Table) Time(name string, options ...ColumnOption) {
 	t.Column(name, Time, options...)
 }
 
+// UUID defines a column with name and UUID type.
+func (t *Table) UUID(name string, options ...ColumnOption) {
+	t.Column(name, UUID, options...)
+}
+
+// Binary defines a column with name and Binary type.
+func (t *Table) Binary(name string, options ...ColumnOption) {
+	t.Column(name, Binary, options...)
+}
+
+// Blob defines a column with name and Blob type.
+func (t *Table) Blob(name string, options ...ColumnOption) {
+	t.Column(name, Blob, options...)
+}
+
+// Enum defines a column with name and Enum type that only accepts the given values.
+// PostgreSQL creates a type named <table>_<column> for the column that is dropped with the table, other databases use a native enum or a check constraint.
+func (t *Table) Enum(name string, values []string, options ...ColumnOption) {
+	t.Column(name, Enum, append([]ColumnOption{Values(values)}, options...)...)
+}
+
 // PrimaryKey defines a primary key for table.
 func (t *Table) PrimaryKey(column string, options ...KeyOption) {
 	t.PrimaryKeys([]string{column}, options...)
@@ -110,16 +158,34 @@ func (t *Table) ForeignKey(column string, refTable string, refColumn string, opt
 	t.Definitions = append(t.Definitions, createForeignKey(column, refTable, refColumn, options))
 }
 
+// ForeignKeys defines a composite foreign key, columns reference refColumns of refTable in the same order.
+func (t *Table) ForeignKeys(columns []string, refTable string, refColumns []string, options ...KeyOption) {
+	t.Definitions = append(t.Definitions, createForeignKeys(columns, refTable, refColumns, options))
+}
+
 // Unique defines an unique key for columns.
 func (t *Table) Unique(columns []string, options ...KeyOption) {
 	t.Definitions = append(t.Definitions, createKeys(columns, UniqueKey, options))
 }
 
+// Check defines a named check constraint using sql expression.
+func (t *Table) Check(name string, expr string, options ...KeyOption) {
+	t.Definitions = append(t.Definitions, createCheck(name, expr, options))
+}
+
 // Fragment defines anything using sql fragment.
 func (t *Table) Fragment(fragment string) {
 	t.Definitions = append(t.Definitions, Raw(fragment))
 }
 
+func (t Table) typeName(column Column) string {
+	if column.Type != Enum {
+		return ""
+	}
+
+	return t.Name + "_" + column.Name
+}
+
 func (t Table) description() string {
 	return t.Op.String() + " table " + t.Name
 }
@@ -141,6 +207,75 @@ func (at *AlterTable) DropColumn(name string, options ...ColumnOption) {
 	at.Definitions = append(at.Definitions, dropColumn(name, options))
 }
 
+// ChangeColumn redefines type, nullability and default of an existing column.
+// Values of enum columns can only be added on PostgreSQL, where the version runs outside a transaction.
+// MySQL replaces the whole column definition, options must include everything the column keeps, such as its limit, default and comment.
+func (at *AlterTable) ChangeColumn(name string, typ ColumnType, options ...ColumnOption) {
+	column := changeColumn(name, typ, options)
+	column.TypeName = at.typeName(column)
+	at.Definitions = append(at.Definitions, column)
+}
+
+// SetNotNull disallows nil values in an existing column.
+// The column type is required by databases that must restate the column definition, such as MySQL and MSSQL.
+// The definition is replaced by typ and options: MySQL resets the limit, default, comment, charset and collation
+// of the column unless they're passed as options, and MSSQL resets its limit.
+func (at *AlterTable) SetNotNull(name string, typ ColumnType, options ...ColumnOption) {
+	at.Definitions = append(at.Definitions, changeColumnRequired(name, typ, true, options))
+}
+
+// DropNotNull allows nil values in an existing column.
+// The column type is required by databases that must restate the column definition, such as MySQL and MSSQL,
+// its definition is replaced by typ and options the same way as SetNotNull.
+func (at *AlterTable) DropNotNull(name string, typ ColumnType, options ...ColumnOption) {
+	at.Definitions = append(at.Definitions, changeColumnRequired(name, typ, false, options))
+}
+
+// SetDefault of an existing column.
+// The column type is required to convert the default to the value expected by the column.
+func (at *AlterTable) SetDefault(name string, typ ColumnType, def any, options ...ColumnOption) {
+	at.Definitions = append(at.Definitions, changeColumnDefault(name, typ, def, options))
+}
+
+// DropDefault of an existing column.
+// The column type is required by databases that must restate the column definition.
+func (at *AlterTable) DropDefault(name string, typ ColumnType, options ...ColumnOption) {
+	at.Definitions = append(at.Definitions, changeColumnDefault(name, typ, nil, options))
+}
+
+// ResetIdentity so the next value generated by the identity column of this table is value.
+// SQLite only generates values using a resettable sequence for ID columns defined with Identity option.
+func (at *AlterTable) ResetIdentity(value int) {
+	at.Definitions = append(at.Definitions, IdentityReset{Value: value})
+}
+
+// DropForeignKey by name.
+func (at *AlterTable) DropForeignKey(name string, options ...KeyOption) {
+	at.Definitions = append(at.Definitions, dropKey(name, ForeignKey, options))
+}
+
+// DropUnique key by name.
+func (at *AlterTable) DropUnique(name string, options ...KeyOption) {
+	at.Definitions = append(at.Definitions, dropKey(name, UniqueKey, options))
+}
+
+// DropPrimaryKey of this table.
+// The constraint name is looked up by databases that require it, unless specified using Name option.
+func (at *AlterTable) DropPrimaryKey(options ...KeyOption) {
+	at.Definitions = append(at.Definitions, dropKey("", PrimaryKey, options))
+}
+
+// DropCheck constraint by name.
+func (at *AlterTable) DropCheck(name string, options ...KeyOption) {
+	at.Definitions = append(at.Definitions, dropKey(name, CheckKey, options))
+}
+
+// RenameConstraint of type to a new name.
+// MySQL is only able to rename unique keys, as it renames the underlying index.
+func (at *AlterTable) RenameConstraint(name string, newName string, typ KeyType, options ...KeyOption) {
+	at.Definitions = append(at.Definitions, renameKey(name, newName, typ, options))
+}
+
 func createTable(name string, options []TableOption) Table {
 	table := Table{
 		Op:   SchemaCreate,
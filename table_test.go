@@ -35,6 +35,50 @@ func TestTable(t *testing.T) {
 		}, table.Definitions[len(table.Definitions)-1])
 	})
 
+	t.Run("UUIDID", func(t *testing.T) {
+		table.UUIDID("uuid_id")
+		assert.Equal(t, Column{
+			Name:    "uuid_id",
+			Type:    UUIDID,
+			Primary: true,
+			Default: ExprUUID,
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("UUID", func(t *testing.T) {
+		table.UUID("uuid")
+		assert.Equal(t, Column{
+			Name: "uuid",
+			Type: UUID,
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Char", func(t *testing.T) {
+		table.Char("char", Limit(2))
+		assert.Equal(t, Column{
+			Name:  "char",
+			Type:  Char,
+			Limit: 2,
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Binary", func(t *testing.T) {
+		table.Binary("binary", Limit(16))
+		assert.Equal(t, Column{
+			Name:  "binary",
+			Type:  Binary,
+			Limit: 16,
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("Blob", func(t *testing.T) {
+		table.Blob("blob")
+		assert.Equal(t, Column{
+			Name: "blob",
+			Type: Blob,
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
 	t.Run("IDNotPrimaryKey", func(t *testing.T) {
 		table.ID("id", Primary(false))
 		assert.Equal(t, Column{
@@ -159,6 +203,18 @@ func TestTable(t *testing.T) {
 		}, table.Definitions[len(table.Definitions)-1])
 	})
 
+	t.Run("ForeignKeys", func(t *testing.T) {
+		table.ForeignKeys([]string{"product_id", "variant_id"}, "variants", []string{"product_id", "id"})
+		assert.Equal(t, Key{
+			Columns: []string{"product_id", "variant_id"},
+			Type:    ForeignKey,
+			Reference: ForeignKeyReference{
+				Table:   "variants",
+				Columns: []string{"product_id", "id"},
+			},
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
 	t.Run("Unique", func(t *testing.T) {
 		table.Unique([]string{"username"})
 		assert.Equal(t, Key{
@@ -167,6 +223,16 @@ func TestTable(t *testing.T) {
 		}, table.Definitions[len(table.Definitions)-1])
 	})
 
+	t.Run("Check", func(t *testing.T) {
+		table.Check("age_positive", "age > 0")
+		assert.Equal(t, Key{
+			Op:         SchemaCreate,
+			Name:       "age_positive",
+			Type:       CheckKey,
+			Expression: "age > 0",
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
 	t.Run("Fragment", func(t *testing.T) {
 		table.Fragment("SQL")
 		assert.Equal(t, Raw("SQL"), table.Definitions[len(table.Definitions)-1])
@@ -192,6 +258,107 @@ func TestAlterTable(t *testing.T) {
 			Name: "column",
 		}, table.Definitions[len(table.Definitions)-1])
 	})
+
+	t.Run("ChangeColumn", func(t *testing.T) {
+		table.ChangeColumn("column", Int, Required(true), Using("column::int"))
+		assert.Equal(t, Column{
+			Op:       SchemaAlter,
+			Change:   ChangeDefinition,
+			Name:     "column",
+			Type:     Int,
+			Required: true,
+			Using:    "column::int",
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("SetNotNull", func(t *testing.T) {
+		table.SetNotNull("column", String, Limit(100))
+		assert.Equal(t, Column{
+			Op:       SchemaAlter,
+			Change:   ChangeRequired,
+			Name:     "column",
+			Type:     String,
+			Limit:    100,
+			Required: true,
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("DropNotNull", func(t *testing.T) {
+		table.DropNotNull("column", String, Required(true))
+		assert.Equal(t, Column{
+			Op:     SchemaAlter,
+			Change: ChangeRequired,
+			Name:   "column",
+			Type:   String,
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("SetDefault", func(t *testing.T) {
+		table.SetDefault("column", String, "default", Limit(100))
+		assert.Equal(t, Column{
+			Op:      SchemaAlter,
+			Change:  ChangeDefault,
+			Name:    "column",
+			Type:    String,
+			Limit:   100,
+			Default: "default",
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("DropDefault", func(t *testing.T) {
+		table.DropDefault("column", String)
+		assert.Equal(t, Column{
+			Op:     SchemaAlter,
+			Change: ChangeDefault,
+			Name:   "column",
+			Type:   String,
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("DropForeignKey", func(t *testing.T) {
+		table.DropForeignKey("fk")
+		assert.Equal(t, Key{
+			Op:   SchemaDrop,
+			Type: ForeignKey,
+			Name: "fk",
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("DropUnique", func(t *testing.T) {
+		table.DropUnique("uq")
+		assert.Equal(t, Key{
+			Op:   SchemaDrop,
+			Type: UniqueKey,
+			Name: "uq",
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("DropPrimaryKey", func(t *testing.T) {
+		table.DropPrimaryKey()
+		assert.Equal(t, Key{
+			Op:   SchemaDrop,
+			Type: PrimaryKey,
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("DropCheck", func(t *testing.T) {
+		table.DropCheck("age_positive")
+		assert.Equal(t, Key{
+			Op:   SchemaDrop,
+			Type: CheckKey,
+			Name: "age_positive",
+		}, table.Definitions[len(table.Definitions)-1])
+	})
+
+	t.Run("RenameConstraint", func(t *testing.T) {
+		table.RenameConstraint("uq", "uq_code", UniqueKey)
+		assert.Equal(t, Key{
+			Op:     SchemaRename,
+			Name:   "uq",
+			Type:   UniqueKey,
+			Rename: "uq_code",
+		}, table.Definitions[len(table.Definitions)-1])
+	})
 }
 
 func TestCreateTable(t *testing.T) {
@@ -199,14 +366,20 @@ func TestCreateTable(t *testing.T) {
 		options = []TableOption{
 			Options("options"),
 			Optional(true),
+			Charset("utf8mb4"),
+			Collation("utf8mb4_unicode_ci"),
+			Comment("comment"),
 		}
 		table = createTable("table", options)
 	)
 
 	assert.Equal(t, Table{
-		Name:     "table",
-		Optional: true,
-		Options:  "options",
+		Name:      "table",
+		Optional:  true,
+		Charset:   "utf8mb4",
+		Collation: "utf8mb4_unicode_ci",
+		Comment:   "comment",
+		Options:   "options",
 	}, table)
 }
 
@@ -217,3 +390,7 @@ func TestTable_Description(t *testing.T) {
 func TestTable_InternalMigration(t *testing.T) {
 	assert.NotPanics(t, func() { Table{}.internalMigration() })
 }
+
+func TestIdentityReset_InternalTableDefinition(t *testing.T) {
+	assert.NotPanics(t, func() { IdentityReset{}.internalTableDefinition() })
+}
@@ -0,0 +1,32 @@
+package dbm
+
+// Type definition of user defined database types, such as PostgreSQL enum.
+type Type struct {
+	Op       SchemaOp
+	Name     string
+	Values   []string
+	Optional bool
+	// Comment of the type.
+	Comment string
+}
+
+func (t Type) description() string {
+	return t.Op.String() + " type " + t.Name
+}
+
+func (Type) internalMigration() {}
+
+func alterEnumType(name string, value string) Type {
+	return Type{
+		Op:     SchemaAlter,
+		Name:   name,
+		Values: []string{value},
+	}
+}
+
+func dropType(name string) Type {
+	return Type{
+		Op:   SchemaDrop,
+		Name: name,
+	}
+}